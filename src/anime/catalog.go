@@ -0,0 +1,232 @@
+package anime
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Anime is a catalog entry for a show
+type Anime struct {
+	Slug           string      `json:"slug"`
+	Name           string      `json:"name"`
+	QuoteCount     int         `json:"quoteCount"`
+	CharacterCount int         `json:"characterCount,omitempty"`
+	Characters     []Character `json:"characters,omitempty"`
+}
+
+// Character is a catalog entry for a quoted character
+type Character struct {
+	Slug       string  `json:"slug"`
+	Name       string  `json:"name"`
+	QuoteCount int     `json:"quoteCount"`
+	Anime      []Anime `json:"anime,omitempty"`
+	Quotes     []Quote `json:"quotes,omitempty"`
+}
+
+// catalog lists the distinct anime and characters in the dataset. Entries
+// are keyed by the same normalized names as the filter indexes, characters
+// by their anime as well, since different shows reuse the same names.
+type catalog struct {
+	anime       []Anime
+	characters  []Character
+	animeKeys   []string
+	charKeys    []charKey
+	animeSlugs  map[string]int
+	charSlugs   map[string]int
+	charIndex   map[charKey]int
+	charsByName map[string][]int
+	charNames   []NameCount
+	charByAnime map[string]map[string][]int
+}
+
+// charKey identifies a character within one anime
+type charKey struct {
+	anime     string
+	character string
+}
+
+// buildCatalog groups the quotes into anime and character entries
+func (d *dataset) buildCatalog() *catalog {
+	c := &catalog{
+		animeSlugs:  make(map[string]int),
+		charSlugs:   make(map[string]int),
+		charIndex:   make(map[charKey]int),
+		charsByName: make(map[string][]int),
+		charByAnime: make(map[string]map[string][]int),
+	}
+
+	for i, q := range d.quotes {
+		animeKey := normalizeName(q.Anime)
+		if c.charByAnime[animeKey] == nil {
+			c.charByAnime[animeKey] = make(map[string][]int)
+		}
+		charName := normalizeName(q.Character)
+		c.charByAnime[animeKey][charName] = append(c.charByAnime[animeKey][charName], i)
+	}
+
+	c.animeKeys = sortedKeys(d.byAnime)
+	for _, key := range c.animeKeys {
+		name := displayName(d.quotes, d.byAnime[key], func(q Quote) string { return q.Anime })
+		c.animeSlugs[Slugify(name)] = len(c.anime)
+		c.anime = append(c.anime, Anime{
+			Slug:           Slugify(name),
+			Name:           name,
+			QuoteCount:     len(d.byAnime[key]),
+			CharacterCount: len(c.charByAnime[key]),
+		})
+	}
+
+	for animeKey, chars := range c.charByAnime {
+		for charName := range chars {
+			c.charKeys = append(c.charKeys, charKey{anime: animeKey, character: charName})
+		}
+	}
+	slices.SortFunc(c.charKeys, func(x, y charKey) int {
+		if n := strings.Compare(x.character, y.character); n != 0 {
+			return n
+		}
+		return strings.Compare(x.anime, y.anime)
+	})
+	for i, key := range c.charKeys {
+		c.charIndex[key] = i
+		c.charsByName[key.character] = append(c.charsByName[key.character], i)
+	}
+
+	// Characters sharing a name across shows get the anime in their slug,
+	// e.g. rin-blue-exorcist
+	for i, key := range c.charKeys {
+		indexes := c.charByAnime[key.anime][key.character]
+		name := displayName(d.quotes, indexes, func(q Quote) string { return q.Character })
+		slug := Slugify(name)
+		if len(c.charsByName[key.character]) > 1 {
+			j, _ := slices.BinarySearch(c.animeKeys, key.anime)
+			slug = Slugify(name + " " + c.anime[j].Name)
+		}
+		for n := 2; ; n++ {
+			if _, taken := c.charSlugs[slug]; !taken {
+				break
+			}
+			slug = fmt.Sprintf("%s-%d", Slugify(name), n)
+		}
+		c.charSlugs[slug] = i
+		c.characters = append(c.characters, Character{
+			Slug:       slug,
+			Name:       name,
+			QuoteCount: len(indexes),
+		})
+	}
+
+	for _, key := range sortedKeys(d.byCharacter) {
+		name := displayName(d.quotes, d.byCharacter[key], func(q Quote) string { return q.Character })
+		c.charNames = append(c.charNames, NameCount{Name: name, Count: len(d.byCharacter[key])})
+	}
+
+	return c
+}
+
+// animeNames returns the anime names with their quote counts
+func (c *catalog) animeNames() []NameCount {
+	names := make([]NameCount, len(c.anime))
+	for i, a := range c.anime {
+		names[i] = NameCount{Name: a.Name, Count: a.QuoteCount}
+	}
+	return names
+}
+
+// characterNames returns the distinct character names with their quote
+// counts across all anime
+func (c *catalog) characterNames() []NameCount {
+	return c.charNames
+}
+
+// sortedKeys returns the keys of a name index in sorted order
+func sortedKeys(index map[string][]int) []string {
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// ListAnime returns every anime in the dataset sorted by name
+func (s *Service) ListAnime() []Anime {
+	d := s.data()
+	list := make([]Anime, len(d.catalog.anime))
+	copy(list, d.catalog.anime)
+	return list
+}
+
+// ListCharacters returns every character in the dataset sorted by name
+func (s *Service) ListCharacters() []Character {
+	d := s.data()
+	list := make([]Character, len(d.catalog.characters))
+	copy(list, d.catalog.characters)
+	return list
+}
+
+// GetAnime returns an anime and its characters by slug. Unknown slugs are
+// resolved leniently like filter names, so "fma" or "naruto-shippuuden"
+// find their show.
+func (s *Service) GetAnime(slug string) (*Anime, bool) {
+	d := s.data()
+	i, ok := d.catalog.animeSlugs[slug]
+	if !ok {
+		keys := resolveName(d.byAnime, strings.ReplaceAll(slug, "-", " "))
+		if len(keys) != 1 {
+			return nil, false
+		}
+		i, _ = slices.BinarySearch(d.catalog.animeKeys, keys[0])
+	}
+
+	a := d.catalog.anime[i]
+	animeKey := d.catalog.animeKeys[i]
+	for charName := range d.catalog.charByAnime[animeKey] {
+		j := d.catalog.charIndex[charKey{anime: animeKey, character: charName}]
+		a.Characters = append(a.Characters, d.catalog.characters[j])
+	}
+	slices.SortFunc(a.Characters, func(x, y Character) int {
+		if x.QuoteCount != y.QuoteCount {
+			return y.QuoteCount - x.QuoteCount
+		}
+		return strings.Compare(x.Name, y.Name)
+	})
+	return &a, true
+}
+
+// GetCharacter returns a character with their anime and quotes by slug.
+// Unknown slugs are resolved leniently like filter names, as long as only
+// one anime has a character of that name.
+func (s *Service) GetCharacter(slug string) (*Character, bool) {
+	d := s.data()
+	i, ok := d.catalog.charSlugs[slug]
+	if !ok {
+		keys := resolveName(d.byCharacter, strings.ReplaceAll(slug, "-", " "))
+		if len(keys) != 1 || len(d.catalog.charsByName[keys[0]]) != 1 {
+			return nil, false
+		}
+		i = d.catalog.charsByName[keys[0]][0]
+	}
+
+	ch := d.catalog.characters[i]
+	key := d.catalog.charKeys[i]
+	for _, idx := range d.catalog.charByAnime[key.anime][key.character] {
+		ch.Quotes = append(ch.Quotes, d.quotes[idx])
+	}
+	j, _ := slices.BinarySearch(d.catalog.animeKeys, key.anime)
+	a := d.catalog.anime[j]
+	ch.Anime = []Anime{{Slug: a.Slug, Name: a.Name, QuoteCount: ch.QuoteCount}}
+	return &ch, true
+}
+
+// GetTotalAnime returns the number of distinct anime
+func (s *Service) GetTotalAnime() int {
+	return len(s.data().catalog.anime)
+}
+
+// GetTotalCharacters returns the number of characters, counting a name
+// once per anime it appears in
+func (s *Service) GetTotalCharacters() int {
+	return len(s.data().catalog.characters)
+}
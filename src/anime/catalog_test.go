@@ -0,0 +1,72 @@
+package anime
+
+import "testing"
+
+func TestCatalogNameCollisions(t *testing.T) {
+	s := newTestService(t,
+		Quote{Anime: "Blue Exorcist", Character: "Rin", Quote: "I'll beat Satan."},
+		Quote{Anime: "Blue Exorcist", Character: "Rin", Quote: "I'm not a demon."},
+		Quote{Anime: "Fate/stay night", Character: "Rin", Quote: "Archer, are you my servant?"},
+		Quote{Anime: "Fate/stay night", Character: "Shirou Emiya", Quote: "People die when they are killed."},
+	)
+
+	var slugs []string
+	for _, ch := range s.ListCharacters() {
+		slugs = append(slugs, ch.Slug)
+	}
+	if want := []string{"rin-blue-exorcist", "rin-fate-stay-night", "shirou-emiya"}; !equalStrings(slugs, want) {
+		t.Errorf("character slugs %q, want %q", slugs, want)
+	}
+	if got := s.GetTotalCharacters(); got != 3 {
+		t.Errorf("GetTotalCharacters() = %d, want 3", got)
+	}
+
+	// Each Rin only has the quotes and anime of their own show
+	tests := []struct {
+		slug   string
+		anime  string
+		quotes int
+	}{
+		{"rin-blue-exorcist", "Blue Exorcist", 2},
+		{"rin-fate-stay-night", "Fate/stay night", 1},
+		{"shirou-emiya", "Fate/stay night", 1},
+		{"shirou", "Fate/stay night", 1},
+	}
+	for _, tt := range tests {
+		ch, ok := s.GetCharacter(tt.slug)
+		if !ok {
+			t.Errorf("GetCharacter(%q) not found", tt.slug)
+			continue
+		}
+		if ch.QuoteCount != tt.quotes || len(ch.Quotes) != tt.quotes {
+			t.Errorf("GetCharacter(%q): %d quotes (count %d), want %d", tt.slug, len(ch.Quotes), ch.QuoteCount, tt.quotes)
+		}
+		for _, q := range ch.Quotes {
+			if q.Anime != tt.anime {
+				t.Errorf("GetCharacter(%q): quote from %q, want %q", tt.slug, q.Anime, tt.anime)
+			}
+		}
+		if len(ch.Anime) != 1 || ch.Anime[0].Name != tt.anime || ch.Anime[0].QuoteCount != tt.quotes {
+			t.Errorf("GetCharacter(%q): anime %+v, want %s only", tt.slug, ch.Anime, tt.anime)
+		}
+	}
+
+	// A bare shared name is ambiguous
+	if ch, ok := s.GetCharacter("rin"); ok {
+		t.Errorf("GetCharacter(rin) = %q, want not found", ch.Slug)
+	}
+
+	a, ok := s.GetAnime("blue-exorcist")
+	if !ok {
+		t.Fatalf("GetAnime(blue-exorcist) not found")
+	}
+	if len(a.Characters) != 1 || a.Characters[0].Slug != "rin-blue-exorcist" || a.Characters[0].QuoteCount != 2 {
+		t.Errorf("GetAnime(blue-exorcist) characters %+v, want rin-blue-exorcist with 2 quotes", a.Characters)
+	}
+
+	// Suggestions still list a shared name once, counting every show
+	suggestions := s.SuggestCharacters("ri", "", 10)
+	if len(suggestions) != 1 || suggestions[0].Name != "Rin" || suggestions[0].Count != 3 {
+		t.Errorf("SuggestCharacters(ri) = %+v, want Rin with 3 quotes", suggestions)
+	}
+}
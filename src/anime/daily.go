@@ -0,0 +1,124 @@
+package anime
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormat is the layout of the dates that key the quote of the day
+const DateFormat = "2006-01-02"
+
+// DailyQuote returns the quote of the day for the given calendar date, or
+// nil if no quotes are loaded.
+//
+// Days walk through a shuffled cycle of every quote, so no quote repeats
+// until all of them have been shown. Each cycle's order is seeded by its
+// number alone and quotes are ordered by ID before shuffling, so the pick
+// only depends on the date and the quotes themselves: it is the same after
+// a restart and on every instance serving the same quotes.
+func (s *Service) DailyQuote(date time.Time) *Quote {
+	d := s.data()
+	n := int64(len(d.daily))
+	if n == 0 {
+		return nil
+	}
+
+	day := daysSinceEpoch(date)
+	cycle, pos := day/n, day%n
+	if pos < 0 {
+		cycle, pos = cycle-1, pos+n
+	}
+
+	order := shuffledCycle(d.daily, "daily-cycle:", cycle)
+	q := d.quotes[order[pos]]
+	return &q
+}
+
+// SequenceQuote returns the nth quote (counting from 0) of the sequence the
+// seed defines over the quotes matching the filter, or nil if no quote
+// matches. Like the daily quotes, the sequence walks shuffled cycles of
+// every matching quote, so no quote repeats until all have been shown, and
+// the same seed, filter and position always give the same quote. A seeded
+// random pick is the sequence's first quote.
+func (s *Service) SequenceQuote(f Filter, seed string, n int64) *Quote {
+	d := s.data()
+	indexes := d.filterIndexes(f)
+	if len(indexes) == 0 || n < 0 {
+		return nil
+	}
+	d.sortByID(indexes)
+
+	size := int64(len(indexes))
+	order := shuffledCycle(indexes, "sequence-cycle:"+seed+":", n/size)
+	q := d.quotes[order[n%size]]
+	return &q
+}
+
+// shuffledCycle returns the shuffled order of indexes for one cycle, keyed
+// by prefix and the cycle number. If the cycle would open with the entry
+// that closed the previous one, the first two entries are swapped so the
+// same quote is never shown twice in a row.
+func shuffledCycle(indexes []int, prefix string, cycle int64) []int {
+	if len(indexes) == 2 {
+		// Every cycle must open with the entry the previous one did not
+		// close with, which leaves one order for all of them
+		return seededShuffle(indexes, prefix+"0")
+	}
+
+	order := seededShuffle(indexes, prefix+strconv.FormatInt(cycle, 10))
+	if len(order) > 2 {
+		// The swap only moves the first two entries, so the previous
+		// cycle ends the same whether or not it was swapped itself
+		prev := seededShuffle(indexes, prefix+strconv.FormatInt(cycle-1, 10))
+		if order[0] == prev[len(prev)-1] {
+			order[0], order[1] = order[1], order[0]
+		}
+	}
+	return order
+}
+
+// daysSinceEpoch returns the number of calendar days between 1970-01-01
+// and the date, ignoring the time of day and zone
+func daysSinceEpoch(date time.Time) int64 {
+	y, m, dd := date.Date()
+	day := time.Date(y, m, dd, 0, 0, 0, 0, time.UTC)
+	return day.Unix() / 86400
+}
+
+// seededShuffle returns a copy of indexes shuffled by a generator seeded
+// from key. It uses its own Fisher-Yates loop over PCG output rather than
+// rand.Shuffle, so the order cannot change between Go releases.
+func seededShuffle(indexes []int, key string) []int {
+	sum := sha256.Sum256([]byte(key))
+	pcg := rand.NewPCG(binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16]))
+
+	order := slices.Clone(indexes)
+	for i := len(order) - 1; i > 0; i-- {
+		j := pcg.Uint64() % uint64(i+1)
+		order[i], order[j] = order[j], order[i]
+	}
+	return order
+}
+
+// sortByID orders quote indexes by quote ID
+func (d *dataset) sortByID(indexes []int) {
+	slices.SortFunc(indexes, func(a, b int) int {
+		return strings.Compare(d.quotes[a].ID, d.quotes[b].ID)
+	})
+}
+
+// buildDailyOrder lists the quote indexes sorted by ID, so the daily pick
+// does not depend on the order quotes and packs were loaded in
+func (d *dataset) buildDailyOrder() []int {
+	order := make([]int, len(d.quotes))
+	for i := range order {
+		order[i] = i
+	}
+	d.sortByID(order)
+	return order
+}
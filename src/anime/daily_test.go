@@ -0,0 +1,79 @@
+package anime
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// numberedQuotes returns n quotes with distinct texts
+func numberedQuotes(n int) []Quote {
+	quotes := make([]Quote, n)
+	for i := range quotes {
+		quotes[i] = Quote{Anime: "Naruto", Character: "Naruto Uzumaki", Quote: fmt.Sprintf("Quote number %d.", i)}
+	}
+	return quotes
+}
+
+// checkCycles checks that picks walk whole cycles of n distinct quotes and
+// never show the same quote twice in a row, including across cycles
+func checkCycles(t *testing.T, what string, n int, picks []string) {
+	t.Helper()
+	for i := 1; i < len(picks); i++ {
+		if n > 1 && picks[i] == picks[i-1] {
+			t.Errorf("%s, %d quotes: %q repeated at %d", what, n, picks[i], i)
+		}
+	}
+	for start := 0; start+n <= len(picks); start += n {
+		seen := make(map[string]bool)
+		for _, id := range picks[start : start+n] {
+			if seen[id] {
+				t.Errorf("%s, %d quotes: %q shown twice in the cycle at %d", what, n, id, start)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func TestDailyQuoteCycles(t *testing.T) {
+	for n := 1; n <= 5; n++ {
+		s := newTestService(t, numberedQuotes(n)...)
+
+		// Start on a cycle boundary and walk many cycles
+		first := int64(20000) / int64(n) * int64(n)
+		var picks []string
+		for day := first; day < first+int64(100*n); day++ {
+			q := s.DailyQuote(time.Unix(day*86400, 0).UTC())
+			picks = append(picks, q.ID)
+		}
+		checkCycles(t, "daily", n, picks)
+
+		// The pick ignores the time of day and zone
+		date := time.Date(2024, 5, 1, 23, 59, 0, 0, time.FixedZone("", -8*3600))
+		if a, b := s.DailyQuote(date), s.DailyQuote(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)); a.ID != b.ID {
+			t.Errorf("daily, %d quotes: %s and %s differ for the same date", n, a.ID, b.ID)
+		}
+	}
+}
+
+func TestSequenceQuote(t *testing.T) {
+	for n := 1; n <= 5; n++ {
+		s := newTestService(t, numberedQuotes(n)...)
+		var picks []string
+		for i := int64(0); i < int64(100*n); i++ {
+			picks = append(picks, s.SequenceQuote(Filter{}, "seed", i).ID)
+		}
+		checkCycles(t, "sequence", n, picks)
+	}
+
+	s := newTestService(t, numberedQuotes(5)...)
+	if a, b := s.SequenceQuote(Filter{}, "x", 3), s.SequenceQuote(Filter{}, "x", 3); a.ID != b.ID {
+		t.Errorf("same seed and position: %s and %s", a.ID, b.ID)
+	}
+	if q := s.SequenceQuote(Filter{Anime: []string{"Bleach"}}, "x", 0); q != nil {
+		t.Errorf("no matching quotes: got %v", q)
+	}
+	if q := s.SequenceQuote(Filter{}, "x", -1); q != nil {
+		t.Errorf("negative position: got %v", q)
+	}
+}
@@ -0,0 +1,130 @@
+package anime
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// Filter restricts quotes by anime and character name. Anime and Character
+// are resolved with resolveName, so case, accents, punctuation, common
+// aliases and small typos are tolerated. ExcludeAnime only ignores case: a
+// typo must not exclude some other, similarly named show. Multiple values
+// within a field are OR'd together; different fields are AND'd.
+type Filter struct {
+	Anime        []string
+	Character    []string
+	ExcludeAnime []string
+}
+
+// IsEmpty reports whether the filter has no constraints
+func (f Filter) IsEmpty() bool {
+	return len(f.Anime) == 0 && len(f.Character) == 0 && len(f.ExcludeAnime) == 0
+}
+
+// FilterQuotes returns the quotes matching the filter in dataset order
+func (s *Service) FilterQuotes(f Filter) []Quote {
+	d := s.data()
+	indexes := d.filterIndexes(f)
+	quotes := make([]Quote, len(indexes))
+	for i, idx := range indexes {
+		quotes[i] = d.quotes[idx]
+	}
+	return quotes
+}
+
+// GetRandomQuoteFiltered returns a random quote matching the filter, or nil
+// if no quote matches
+func (s *Service) GetRandomQuoteFiltered(f Filter) *Quote {
+	d := s.data()
+	indexes := d.filterIndexes(f)
+	if len(indexes) == 0 {
+		return nil
+	}
+	q := d.quotes[indexes[rand.IntN(len(indexes))]]
+	return &q
+}
+
+// filterIndexes resolves the filter against the name indexes and returns
+// the matching dataset positions in ascending order
+func (d *dataset) filterIndexes(f Filter) []int {
+	var candidates []int
+	constrained := false
+
+	if len(f.Anime) > 0 {
+		candidates = lookupIndexes(d.byAnime, f.Anime)
+		constrained = true
+	}
+	if len(f.Character) > 0 {
+		byCharacter := lookupIndexes(d.byCharacter, f.Character)
+		if constrained {
+			candidates = intersectIndexes(candidates, byCharacter)
+		} else {
+			candidates = byCharacter
+		}
+		constrained = true
+	}
+	if !constrained {
+		candidates = make([]int, len(d.quotes))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+
+	if len(f.ExcludeAnime) > 0 {
+		excluded := make(map[int]bool)
+		for _, i := range d.exactAnimeIndexes(f.ExcludeAnime) {
+			excluded[i] = true
+		}
+		candidates = slices.DeleteFunc(candidates, func(i int) bool {
+			return excluded[i]
+		})
+	}
+
+	return candidates
+}
+
+// exactAnimeIndexes returns the quotes whose anime is one of names,
+// ignoring case and surrounding spaces
+func (d *dataset) exactAnimeIndexes(names []string) []int {
+	var result []int
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		for _, i := range d.byAnime[normalizeName(name)] {
+			if strings.EqualFold(d.quotes[i].Anime, name) {
+				result = append(result, i)
+			}
+		}
+	}
+	return result
+}
+
+// lookupIndexes returns the sorted union of the index entries for names
+func lookupIndexes(index map[string][]int, names []string) []int {
+	var result []int
+	for _, name := range names {
+		for _, key := range resolveName(index, name) {
+			result = append(result, index[key]...)
+		}
+	}
+	slices.Sort(result)
+	return slices.Compact(result)
+}
+
+// intersectIndexes returns the indexes present in both sorted slices
+func intersectIndexes(a, b []int) []int {
+	var result []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
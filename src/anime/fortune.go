@@ -0,0 +1,106 @@
+package anime
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// fortuneWidth is the column quotes are wrapped at in fortune files
+const fortuneWidth = 72
+
+// fortuneDelimiter separates entries in a fortune file
+const fortuneDelimiter = "%\n"
+
+// strfileVersion is the .dat format version written by strfile(8)
+const strfileVersion = 2
+
+// FormatFortune formats a quote as a fortune(6) entry: the quote wrapped at
+// 72 columns followed by an indented attribution line. A line holding only
+// "%" is indented by a space so it is not read as the end of the entry.
+func FormatFortune(q Quote) string {
+	var sb strings.Builder
+	writeLine := func(line string) {
+		if line+"\n" == fortuneDelimiter {
+			line = " " + line
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	line := ""
+	for _, word := range strings.Fields(q.Quote) {
+		if line != "" && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > fortuneWidth {
+			writeLine(line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		writeLine(line)
+	}
+	sb.WriteString("\t\t-- " + q.Character + ", " + q.Anime + "\n")
+	return sb.String()
+}
+
+// WriteFortunes writes quotes as a fortune file, each entry followed by a
+// line holding a single "%"
+func WriteFortunes(w io.Writer, quotes []Quote) error {
+	bw := bufio.NewWriter(w)
+	for _, q := range quotes {
+		bw.WriteString(FormatFortune(q))
+		bw.WriteString(fortuneDelimiter)
+	}
+	return bw.Flush()
+}
+
+// WriteStrfile writes the random-access index strfile(8) builds for the
+// fortune file WriteFortunes produces from the same quotes. The header and
+// offsets are big-endian 32-bit values: version, entry count, longest and
+// shortest entry length, flags and the delimiter character, then the start
+// offset of every entry followed by the end of the file.
+func WriteStrfile(w io.Writer, quotes []Quote) error {
+	offsets := make([]uint32, 0, len(quotes)+1)
+	var pos, longest uint32
+	shortest := ^uint32(0)
+
+	offsets = append(offsets, 0)
+	for _, q := range quotes {
+		length := uint32(len(FormatFortune(q)))
+		longest = max(longest, length)
+		shortest = min(shortest, length)
+		pos += length + uint32(len(fortuneDelimiter))
+		offsets = append(offsets, pos)
+	}
+	if len(quotes) == 0 {
+		shortest = 0
+	}
+
+	header := struct {
+		Version  uint32
+		NumStr   uint32
+		LongLen  uint32
+		ShortLen uint32
+		Flags    uint32
+		Delim    [4]byte
+	}{
+		Version:  strfileVersion,
+		NumStr:   uint32(len(quotes)),
+		LongLen:  longest,
+		ShortLen: shortest,
+		Delim:    [4]byte{fortuneDelimiter[0]},
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := binary.Write(bw, binary.BigEndian, header); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, binary.BigEndian, offsets); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
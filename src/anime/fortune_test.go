@@ -0,0 +1,93 @@
+package anime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+var fortuneQuotes = []Quote{
+	{Anime: "Naruto", Character: "Naruto", Quote: "Believe it!"},
+	{Anime: "Amélie", Character: "Amélie", Quote: "Ça va, café crème."},
+	{Anime: "One Piece", Character: "Monkey D. Luffy", Quote: "If you don't take risks, you can't create a future! I'm gonna be King of the Pirates!"},
+	// Wraps to a line holding only "%", which must not end the entry
+	{Anime: "Steins;Gate", Character: "Rintaro Okabe", Quote: "The chance of changing the world line on the first try? I'd say about 1 %"},
+}
+
+// fortuneGolden is the fortune file for fortuneQuotes; entries are 32, 44,
+// 118 and 107 bytes long without their "%" line
+const fortuneGolden = "Believe it!\n" +
+	"\t\t-- Naruto, Naruto\n" +
+	"%\n" +
+	"Ça va, café crème.\n" +
+	"\t\t-- Amélie, Amélie\n" +
+	"%\n" +
+	"If you don't take risks, you can't create a future! I'm gonna be King of\n" +
+	"the Pirates!\n" +
+	"\t\t-- Monkey D. Luffy, One Piece\n" +
+	"%\n" +
+	"The chance of changing the world line on the first try? I'd say about 1\n" +
+	" %\n" +
+	"\t\t-- Rintaro Okabe, Steins;Gate\n" +
+	"%\n"
+
+// strfileGolden is what strfile(8) writes for fortuneGolden: a version 2
+// header of big-endian 32-bit fields, then the offset of each entry and of
+// the end of the file
+const strfileGolden = "" +
+	"00000002" + // version
+	"00000004" + // number of entries
+	"00000076" + // longest entry, 118 bytes
+	"00000020" + // shortest entry, 32 bytes
+	"00000000" + // flags: not random, ordered or rotated
+	"25000000" + // delimiter '%' and padding
+	"00000000" + // entry 1
+	"00000022" + // entry 2 at 32+2
+	"00000050" + // entry 3 at 34+44+2
+	"000000c8" + // entry 4 at 80+118+2
+	"00000135" // end of file at 200+107+2
+
+func TestWriteFortunes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFortunes(&buf, fortuneQuotes); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != fortuneGolden {
+		t.Errorf("WriteFortunes:\n%s\nwant:\n%s", got, fortuneGolden)
+	}
+}
+
+func TestWriteStrfile(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStrfile(&buf, fortuneQuotes); err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(buf.Bytes()); got != strfileGolden {
+		t.Errorf("WriteStrfile = %s\nwant          %s", got, strfileGolden)
+	}
+
+	// Every offset but the last starts an entry and follows a "%" line
+	dat := buf.Bytes()
+	for i := 0; i < len(fortuneQuotes); i++ {
+		pos := int(binary.BigEndian.Uint32(dat[24+4*i:]))
+		if !strings.HasPrefix(fortuneGolden[pos:], strings.Fields(fortuneQuotes[i].Quote)[0]) {
+			t.Errorf("offset %d = %d does not start entry %d", i, pos, i+1)
+		}
+		if pos > 0 && fortuneGolden[pos-2:pos] != fortuneDelimiter {
+			t.Errorf("offset %d = %d does not follow a delimiter", i, pos)
+		}
+	}
+}
+
+func TestWriteStrfileEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStrfile(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "00000002" + "00000000" + "00000000" + "00000000" + "00000000" + "25000000" + "00000000"
+	if got := hex.EncodeToString(buf.Bytes()); got != want {
+		t.Errorf("WriteStrfile(nil) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,259 @@
+package anime
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported quote file formats
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+// FormatFromPath returns the quote file format for a file name, or an empty
+// string if the extension is not supported
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".csv":
+		return FormatCSV
+	default:
+		return ""
+	}
+}
+
+// ParseQuotes parses a quote list in the given format. JSON and YAML files
+// hold a list of objects with anime, character and quote fields; CSV files
+// need a header row naming those columns in any order.
+func ParseQuotes(data []byte, format string) ([]Quote, error) {
+	var quotes []Quote
+	switch format {
+	case FormatJSON:
+		if err := json.Unmarshal(data, &quotes); err != nil {
+			return nil, fmt.Errorf("failed to parse quotes: %w", err)
+		}
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &quotes); err != nil {
+			return nil, fmt.Errorf("failed to parse quotes: %w", err)
+		}
+	case FormatCSV:
+		return parseQuotesCSV(data)
+	default:
+		return nil, fmt.Errorf("unsupported quote format %q", format)
+	}
+	return quotes, nil
+}
+
+// parseQuotesCSV parses CSV quote data with a header row
+func parseQuotesCSV(data []byte) ([]Quote, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"anime", "character", "quote"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %q column", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i := columns[name]; i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var quotes []Quote
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV: %w", err)
+		}
+		quotes = append(quotes, Quote{
+			Anime:     field(record, "anime"),
+			Character: field(record, "character"),
+			Quote:     field(record, "quote"),
+		})
+	}
+	return quotes, nil
+}
+
+// LoadQuotesFile reads and parses a quote file, picking the format from
+// its extension
+func LoadQuotesFile(path string) ([]Quote, error) {
+	format := FormatFromPath(path)
+	if format == "" {
+		return nil, fmt.Errorf("%s: unsupported file extension", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	quotes, err := ParseQuotes(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return quotes, nil
+}
+
+// PackFiles lists the supported quote files in dir in lexical order. A
+// missing directory has no files.
+func PackFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") || FormatFromPath(e.Name()) == "" {
+			continue
+		}
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// packDirState fingerprints the quote files in dir by name, size and
+// modification time so the watcher can tell when something changed
+func packDirState(dir string) (string, error) {
+	files, err := PackFiles(dir)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// LoadDir merges the quote packs in dir on top of the embedded quotes. The
+// directory is remembered for Reload and Watch.
+func (s *Service) LoadDir(dir string) error {
+	s.mu.Lock()
+	s.dir = dir
+	s.mu.Unlock()
+	return s.Reload()
+}
+
+// Reload re-reads the quote pack directory and swaps in a new dataset. If
+// any file fails to parse, the current dataset is kept and an error naming
+// the file is returned.
+func (s *Service) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dir == "" {
+		return nil
+	}
+
+	state, err := packDirState(s.dir)
+	if err != nil {
+		return err
+	}
+	files, err := PackFiles(s.dir)
+	if err != nil {
+		return err
+	}
+
+	quotes := make([]Quote, len(s.base), len(s.base)+64)
+	copy(quotes, s.base)
+	seen := make(map[string]bool, len(s.base))
+	for _, q := range s.base {
+		seen[quoteID(q)] = true
+	}
+
+	for _, file := range files {
+		pack, err := LoadQuotesFile(file)
+		if err != nil {
+			return err
+		}
+		for _, q := range pack {
+			// Skip blank entries and quotes already in the dataset
+			if strings.TrimSpace(q.Quote) == "" || seen[quoteID(q)] {
+				continue
+			}
+			seen[quoteID(q)] = true
+			q.ID = ""
+			quotes = append(quotes, q)
+		}
+	}
+
+	s.current.Store(newDataset(quotes))
+	s.dirState = state
+	return nil
+}
+
+// Watch polls the quote pack directory and reloads whenever its files
+// change. It returns when ctx is cancelled.
+func (s *Service) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			dir, last := s.dir, s.dirState
+			s.mu.Unlock()
+			if dir == "" {
+				continue
+			}
+
+			state, err := packDirState(dir)
+			if err != nil || state == last {
+				continue
+			}
+			if err := s.Reload(); err != nil {
+				log.Printf("Failed to reload quote packs: %v", err)
+				// Remember the broken state so the error is logged once
+				s.mu.Lock()
+				s.dirState = state
+				s.mu.Unlock()
+				continue
+			}
+			log.Printf("Reloaded quote packs from %s (%d quotes)", dir, s.GetTotalQuotes())
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package anime
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var baseQuotes = []Quote{
+	{Anime: "Naruto", Character: "Naruto Uzumaki", Quote: "I never go back on my word."},
+	{Anime: "Bleach", Character: "Ichigo Kurosaki", Quote: "I'm not fighting because I want to win."},
+}
+
+// writePack writes a quote pack file into dir
+func writePack(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+// characters returns the characters of the service's quotes in order
+func characters(s *Service) []string {
+	var names []string
+	for _, q := range s.GetAllQuotes() {
+		names = append(names, q.Character)
+	}
+	return names
+}
+
+func TestLoadDirMergesPacks(t *testing.T) {
+	dir := t.TempDir()
+	writePack(t, dir, "b.yaml", `
+- anime: One Piece
+  character: Luffy
+  quote: I'm gonna be King of the Pirates!
+- anime: Naruto
+  character: Naruto Uzumaki
+  quote: I never go back on my word.
+`)
+	writePack(t, dir, "a.json", `[
+		{"anime": "Death Note", "character": "L", "quote": "Being alone is better than being with the wrong person."},
+		{"anime": "Death Note", "character": "Blank", "quote": "   "}
+	]`)
+	writePack(t, dir, "c.csv", "quote,anime,character\n\"I'm gonna be King of the Pirates!\",One Piece,Luffy\nPeople die when they are killed.,Fate/stay night,Shirou Emiya\n")
+	writePack(t, dir, "notes.txt", "not a quote pack")
+	writePack(t, dir, ".hidden.json", "broken")
+
+	s := newTestService(t, baseQuotes...)
+	if err := s.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+
+	// Embedded quotes first, then packs in file name order; duplicates of
+	// earlier quotes and blank quotes are skipped
+	want := []string{"Naruto Uzumaki", "Ichigo Kurosaki", "L", "Luffy", "Shirou Emiya"}
+	if got := characters(s); !equalStrings(got, want) {
+		t.Errorf("quotes = %q, want %q", got, want)
+	}
+
+	// Pack quotes are indexed like embedded ones
+	if quotes := s.FilterQuotes(Filter{Anime: []string{"death note"}}); len(quotes) != 1 {
+		t.Errorf("filter by pack anime: got %d quotes, want 1", len(quotes))
+	}
+	if _, total := s.Search("pirates", Filter{}, 0); total != 1 {
+		t.Errorf("search pack quote: got %d results, want 1", total)
+	}
+	for _, q := range s.GetAllQuotes() {
+		if got, ok := s.GetQuoteByID(q.ID); !ok || got.Quote != q.Quote {
+			t.Errorf("GetQuoteByID(%q) = %v, %v", q.ID, got, ok)
+		}
+	}
+}
+
+func TestReloadAddRemoveAndBreakPack(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestService(t, baseQuotes...)
+	if err := s.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir on empty dir: %v", err)
+	}
+	if got := s.GetTotalQuotes(); got != 2 {
+		t.Fatalf("empty dir: %d quotes, want 2", got)
+	}
+
+	// Adding a pack
+	writePack(t, dir, "extra.json", `[{"anime": "Death Note", "character": "L", "quote": "Being alone is better than being with the wrong person."}]`)
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Reload after adding: %v", err)
+	}
+	if got := s.GetTotalQuotes(); got != 3 {
+		t.Fatalf("after adding: %d quotes, want 3", got)
+	}
+	id := s.GetAllQuotes()[2].ID
+
+	// Breaking it keeps the current quotes and names the file
+	writePack(t, dir, "extra.json", `[{"anime": "Death Note",`)
+	err := s.Reload()
+	if err == nil || !strings.Contains(err.Error(), "extra.json") {
+		t.Fatalf("Reload of broken pack: error %v, want one naming extra.json", err)
+	}
+	if got := s.GetTotalQuotes(); got != 3 {
+		t.Errorf("after breaking: %d quotes, want the previous 3", got)
+	}
+	if _, ok := s.GetQuoteByID(id); !ok {
+		t.Errorf("after breaking: quote %s is gone", id)
+	}
+
+	// Removing it goes back to the embedded quotes
+	if err := os.Remove(filepath.Join(dir, "extra.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Reload after removing: %v", err)
+	}
+	if got := characters(s); !equalStrings(got, []string{"Naruto Uzumaki", "Ichigo Kurosaki"}) {
+		t.Errorf("after removing: %q", got)
+	}
+	if _, ok := s.GetQuoteByID(id); ok {
+		t.Errorf("after removing: quote %s still served", id)
+	}
+}
+
+// logBuffer collects log output; the watcher logs from its own goroutine
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// count returns how often s was logged
+func (b *logBuffer) count(s string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Count(b.buf.String(), s)
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatchReloadsAndLogsBrokenPackOnce(t *testing.T) {
+	var logs logBuffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	dir := t.TempDir()
+	s := newTestService(t, baseQuotes...)
+	if err := s.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Watch(ctx, 5*time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	writePack(t, dir, "extra.json", `[{"anime": "Death Note", "character": "L", "quote": "Being alone is better than being with the wrong person."}]`)
+	waitFor(t, "the new pack", func() bool { return s.GetTotalQuotes() == 3 })
+
+	writePack(t, dir, "extra.json", `{broken`)
+	waitFor(t, "the reload error", func() bool { return logs.count("Failed to reload quote packs") > 0 })
+
+	// The broken state is remembered, so later polls stay quiet
+	time.Sleep(50 * time.Millisecond)
+	if n := logs.count("Failed to reload quote packs"); n != 1 {
+		t.Errorf("reload error logged %d times, want once", n)
+	}
+	if got := s.GetTotalQuotes(); got != 3 {
+		t.Errorf("broken pack: %d quotes, want the previous 3", got)
+	}
+
+	// Fixing the file reloads again
+	writePack(t, dir, "extra.json", `[]`)
+	waitFor(t, "the fixed pack", func() bool { return s.GetTotalQuotes() == 2 })
+}
@@ -0,0 +1,232 @@
+package anime
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// nameAliases maps common abbreviations and alternate titles to the names
+// used in the dataset. Aliases that point at names missing from the loaded
+// dataset are simply ignored.
+var nameAliases = map[string][]string{
+	"fma":                   {"Fullmetal Alchemist", "Fullmetal Alchemist: Brotherhood"},
+	"fmab":                  {"Fullmetal Alchemist: Brotherhood"},
+	"aot":                   {"Shingeki no Kyojin", "Attack on Titan"},
+	"snk":                   {"Shingeki no Kyojin", "Attack on Titan"},
+	"attack on titan":       {"Shingeki no Kyojin"},
+	"shingeki no kyojin":    {"Attack on Titan"},
+	"mha":                   {"Boku no Hero Academia", "My Hero Academia"},
+	"bnha":                  {"Boku no Hero Academia", "My Hero Academia"},
+	"my hero academia":      {"Boku no Hero Academia"},
+	"boku no hero academia": {"My Hero Academia"},
+	"hxh":                   {"Hunter x Hunter"},
+	"jjk":                   {"Jujutsu Kaisen"},
+	"kny":                   {"Kimetsu no Yaiba", "Demon Slayer"},
+	"demon slayer":          {"Kimetsu no Yaiba"},
+	"kimetsu no yaiba":      {"Demon Slayer"},
+	"dbz":                   {"Dragon Ball Z"},
+	"eva":                   {"Neon Genesis Evangelion"},
+	"nge":                   {"Neon Genesis Evangelion"},
+	"opm":                   {"One Punch Man"},
+	"op":                    {"One Piece"},
+	"sao":                   {"Sword Art Online"},
+	"jojo":                  {"JoJo's Bizarre Adventure"},
+	"cb":                    {"Cowboy Bebop"},
+	"dn":                    {"Death Note"},
+	"tg":                    {"Tokyo Ghoul"},
+}
+
+// aliasIndex is nameAliases keyed by normalized alias
+var aliasIndex = func() map[string][]string {
+	index := make(map[string][]string, len(nameAliases))
+	for alias, names := range nameAliases {
+		index[normalizeName(alias)] = names
+	}
+	return index
+}()
+
+// foldTable maps accented Latin letters to their unaccented base letter
+var foldTable = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a', 'ă': 'a', 'ą': 'a',
+	'ç': 'c', 'ć': 'c', 'č': 'c',
+	'ď': 'd', 'đ': 'd',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e', 'ė': 'e', 'ę': 'e', 'ě': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i', 'į': 'i',
+	'ł': 'l',
+	'ñ': 'n', 'ń': 'n', 'ň': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o', 'ő': 'o',
+	'ř': 'r',
+	'ś': 's', 'š': 's', 'ş': 's',
+	'ť': 't', 'ţ': 't',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u', 'ů': 'u', 'ű': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'ź': 'z', 'ż': 'z', 'ž': 'z',
+}
+
+// foldRune lowercases r and strips diacritics from Latin letters
+func foldRune(r rune) rune {
+	r = unicode.ToLower(r)
+	if folded, ok := foldTable[r]; ok {
+		return folded
+	}
+	return r
+}
+
+// isVowel reports whether r is a lowercase ASCII vowel
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiou", r)
+}
+
+// nameWords folds case and diacritics and splits a name into words of
+// letters and digits. Apostrophes join words ("JoJo's" -> "jojos").
+func nameWords(name string) []string {
+	var words []string
+	var sb strings.Builder
+
+	for _, r := range name {
+		r = foldRune(r)
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			continue
+		}
+		if r == '\'' || r == '’' {
+			continue
+		}
+		if sb.Len() > 0 {
+			words = append(words, sb.String())
+			sb.Reset()
+		}
+	}
+	if sb.Len() > 0 {
+		words = append(words, sb.String())
+	}
+
+	return words
+}
+
+// normalizeName folds case and diacritics, replaces punctuation with spaces
+// and collapses repeated vowels, so romanization variants such as
+// "Shippuden", "Shippuuden" and "Shippūden" share a key.
+func normalizeName(name string) string {
+	words := nameWords(name)
+	for i, word := range words {
+		var sb strings.Builder
+		var prev rune
+		for _, r := range word {
+			if r == prev && isVowel(r) {
+				continue
+			}
+			sb.WriteRune(r)
+			prev = r
+		}
+		words[i] = sb.String()
+	}
+	return strings.Join(words, " ")
+}
+
+// Slugify converts a name into a URL-safe slug ("Fullmetal Alchemist:
+// Brotherhood" -> "fullmetal-alchemist-brotherhood")
+func Slugify(name string) string {
+	return strings.Join(nameWords(name), "-")
+}
+
+// levenshtein returns the edit distance between a and b, giving up early
+// and returning limit+1 once the distance is known to exceed limit
+func levenshtein(a, b string, limit int) int {
+	ar, br := []rune(a), []rune(b)
+	if diff := len(ar) - len(br); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(br)]
+}
+
+// maxEditDistance returns how many typos are tolerated for a key of the
+// given length
+func maxEditDistance(key string) int {
+	n := len([]rune(key))
+	switch {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// resolveName maps a user-supplied name onto keys of a name index. It tries,
+// in order: an exact normalized match, the alias table, the closest keys by
+// edit distance, and finally keys that extend the name by whole words
+// (so "Fullmetal Alchemist" also finds "Fullmetal Alchemist: Brotherhood"
+// when the former is not itself in the dataset). A name is never shortened
+// to fit a key: "Death Note Relight" does not match "Death Note".
+func resolveName(index map[string][]int, name string) []string {
+	key := normalizeName(name)
+	if key == "" {
+		return nil
+	}
+	if _, ok := index[key]; ok {
+		return []string{key}
+	}
+
+	var keys []string
+	for _, alias := range aliasIndex[key] {
+		if aliasKey := normalizeName(alias); len(index[aliasKey]) > 0 {
+			keys = append(keys, aliasKey)
+		}
+	}
+	if len(keys) > 0 {
+		return keys
+	}
+
+	if limit := maxEditDistance(key); limit > 0 {
+		best := limit + 1
+		for candidate := range index {
+			d := levenshtein(key, candidate, limit)
+			if d < best {
+				best = d
+				keys = keys[:0]
+			}
+			if d == best && d <= limit {
+				keys = append(keys, candidate)
+			}
+		}
+		if len(keys) > 0 {
+			slices.Sort(keys)
+			return keys
+		}
+	}
+
+	for candidate := range index {
+		if strings.HasPrefix(candidate, key+" ") {
+			keys = append(keys, candidate)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
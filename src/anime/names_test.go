@@ -0,0 +1,178 @@
+package anime
+
+import (
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Naruto Shippuden", "naruto shippuden"},
+		{"Naruto Shippuuden", "naruto shippuden"},
+		{"Naruto Shippūden", "naruto shippuden"},
+		{"NARUTO  shippuden", "naruto shippuden"},
+		{"Fullmetal Alchemist: Brotherhood", "fullmetal alchemist brotherhod"},
+		{"JoJo's Bizarre Adventure", "jojos bizarre adventure"},
+		{"JoJo’s Bizarre Adventure", "jojos bizarre adventure"},
+		{"Amélie Poulain", "amelie poulain"},
+		{"Łódź", "lodz"},
+		{"Kimi no Na wa.", "kimi no na wa"},
+		{"Ōkami", "okami"},
+		{"  --  ", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeName(tt.name); got != tt.want {
+			t.Errorf("normalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Fullmetal Alchemist: Brotherhood", "fullmetal-alchemist-brotherhood"},
+		{"JoJo's Bizarre Adventure", "jojos-bizarre-adventure"},
+		{"Amélie", "amelie"},
+		// Vowels are not collapsed in slugs
+		{"Naruto Shippuuden", "naruto-shippuuden"},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.name); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"naruto", "naruto", 2, 0},
+		{"naruto", "narto", 2, 1},
+		{"naruto", "nartuo", 2, 2},
+		{"bleach", "beach", 1, 1},
+		// Beyond the limit the result is limit+1
+		{"naruto", "bleach", 2, 3},
+		{"abc", "abcdef", 1, 2},
+		// Runes, not bytes
+		{"café", "cafe", 1, 1},
+		{"", "abc", 5, 3},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("levenshtein(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestMaxEditDistance(t *testing.T) {
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"op", 0},
+		{"fma", 0},
+		{"eren", 1},
+		{"naruto", 1},
+		{"shipuden", 2},
+		{"éééé", 1},
+	}
+	for _, tt := range tests {
+		if got := maxEditDistance(tt.key); got != tt.want {
+			t.Errorf("maxEditDistance(%q) = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestResolveName(t *testing.T) {
+	index := map[string][]int{}
+	for i, name := range []string{
+		"Naruto",
+		"Naruto Shippuden",
+		"Fullmetal Alchemist: Brotherhood",
+		"Attack on Titan",
+		"Bleach",
+		"One Piece",
+	} {
+		index[normalizeName(name)] = []int{i}
+	}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		// Exact normalized match wins over longer titles
+		{"naruto", []string{"naruto"}},
+		{"Naruto Shippūden", []string{"naruto shippuden"}},
+		// Aliases, skipping targets missing from the index
+		{"fma", []string{"fullmetal alchemist brotherhod"}},
+		{"AoT", []string{"attack on titan"}},
+		{"Shingeki no Kyojin", []string{"attack on titan"}},
+		// One typo tolerated from four characters, two from eight
+		{"Bleah", []string{"bleach"}},
+		{"Blaech", nil},
+		{"One Pece", []string{"one piece"}},
+		{"Naruto Shipuudn", []string{"naruto shippuden"}},
+		// Too short for typos
+		{"Nar", nil},
+		// Whole-word extension when nothing closer matches
+		{"Fullmetal Alchemist", []string{"fullmetal alchemist brotherhod"}},
+		{"Attack", []string{"attack on titan"}},
+		// but names are not cut down to a shorter key
+		{"One Piece Film Red", nil},
+		{"Naruto Shippuden Movie", nil},
+		{"", nil},
+		{"Dragon Ball", nil},
+	}
+	for _, tt := range tests {
+		if got := resolveName(index, tt.name); !equalStrings(got, tt.want) {
+			t.Errorf("resolveName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFilterQuotes(t *testing.T) {
+	s := newTestService(t,
+		Quote{Anime: "Naruto", Character: "Naruto Uzumaki", Quote: "Believe it!"},
+		Quote{Anime: "Naruto Shippuden", Character: "Naruto Uzumaki", Quote: "I never go back on my word."},
+		Quote{Anime: "Bleach", Character: "Ichigo Kurosaki", Quote: "I'm not fighting because I want to win."},
+		Quote{Anime: "Beach Episode", Character: "Narrator", Quote: "Sun and sand."},
+		Quote{Anime: "Fullmetal Alchemist: Brotherhood", Character: "Edward Elric", Quote: "A lesson without pain is meaningless."},
+	)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string // quotes' characters
+	}{
+		{"anime alias", Filter{Anime: []string{"fmab"}}, []string{"Edward Elric"}},
+		{"anime typo", Filter{Anime: []string{"Bleech"}}, []string{"Ichigo Kurosaki"}},
+		{"character", Filter{Character: []string{"naruto uzumaki"}}, []string{"Naruto Uzumaki", "Naruto Uzumaki"}},
+		{"anime and character", Filter{Anime: []string{"Naruto"}, Character: []string{"Naruto Uzumaki"}}, []string{"Naruto Uzumaki"}},
+		{"any of several", Filter{Anime: []string{"Bleach", "Naruto"}}, []string{"Naruto Uzumaki", "Ichigo Kurosaki"}},
+		{"exclude exact, ignoring case", Filter{ExcludeAnime: []string{" bleach "}}, []string{"Naruto Uzumaki", "Naruto Uzumaki", "Narrator", "Edward Elric"}},
+		// A typo must not exclude a similarly named show
+		{"exclude typo", Filter{ExcludeAnime: []string{"Beach"}}, []string{"Naruto Uzumaki", "Naruto Uzumaki", "Ichigo Kurosaki", "Narrator", "Edward Elric"}},
+		{"exclude alias", Filter{ExcludeAnime: []string{"fmab"}}, []string{"Naruto Uzumaki", "Naruto Uzumaki", "Ichigo Kurosaki", "Narrator", "Edward Elric"}},
+		{"exclude does not extend", Filter{Anime: []string{"Naruto Shippuden", "Naruto"}, ExcludeAnime: []string{"Naruto"}}, []string{"Naruto Uzumaki"}},
+		{"no match", Filter{Anime: []string{"Dragon Ball"}}, nil},
+		// Longer names than any in the dataset match nothing
+		{"longer anime", Filter{Anime: []string{"Bleach Thousand Year Blood War"}}, nil},
+		{"longer character", Filter{Character: []string{"Edward Elric Jr"}}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, q := range s.FilterQuotes(tt.filter) {
+			got = append(got, q.Character)
+		}
+		if !equalStrings(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package anime
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// BM25 ranking parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// SearchResult is a single ranked search hit
+type SearchResult struct {
+	Quote      Quote       `json:"quote"`
+	Score      float64     `json:"score"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+// Highlight marks a matched span within a quote field. Start and End are
+// rune offsets into the field value, End being exclusive.
+type Highlight struct {
+	Field string `json:"field"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// searchIndex is an inverted index over quote text, character and anime
+type searchIndex struct {
+	postings  map[string][]posting
+	docLen    []int
+	avgDocLen float64
+}
+
+// posting records how often a term occurs in a quote
+type posting struct {
+	doc int
+	tf  int
+}
+
+// token is a normalized word with its rune offsets in the source text
+type token struct {
+	term  string
+	start int
+	end   int
+}
+
+// quoteField is a named text field of a quote
+type quoteField struct {
+	name  string
+	value string
+}
+
+// searchFields lists the indexed fields of a quote
+func searchFields(q Quote) []quoteField {
+	return []quoteField{
+		{"quote", q.Quote},
+		{"character", q.Character},
+		{"anime", q.Anime},
+	}
+}
+
+// tokenize splits text into words of letters and digits, folded with foldRune
+func tokenize(text string) []token {
+	var tokens []token
+	var sb strings.Builder
+	start := -1
+	pos := 0
+
+	flush := func() {
+		if start >= 0 {
+			tokens = append(tokens, token{term: sb.String(), start: start, end: pos})
+			sb.Reset()
+			start = -1
+		}
+	}
+
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = pos
+			}
+			sb.WriteRune(foldRune(r))
+		} else {
+			flush()
+		}
+		pos++
+	}
+	flush()
+
+	return tokens
+}
+
+// buildSearchIndex indexes every quote for full-text search
+func buildSearchIndex(quotes []Quote) *searchIndex {
+	idx := &searchIndex{
+		postings: make(map[string][]posting),
+		docLen:   make([]int, len(quotes)),
+	}
+
+	total := 0
+	for doc, q := range quotes {
+		counts := make(map[string]int)
+		for _, field := range searchFields(q) {
+			for _, t := range tokenize(field.value) {
+				counts[t.term]++
+				idx.docLen[doc]++
+			}
+		}
+		for term, tf := range counts {
+			idx.postings[term] = append(idx.postings[term], posting{doc: doc, tf: tf})
+		}
+		total += idx.docLen[doc]
+	}
+	if len(quotes) > 0 {
+		idx.avgDocLen = float64(total) / float64(len(quotes))
+	}
+
+	return idx
+}
+
+// parseSearchQuery splits a query into free terms and "quoted phrases"
+func parseSearchQuery(query string) (terms []string, phrases [][]string) {
+	parts := strings.Split(query, `"`)
+	for i, part := range parts {
+		tokens := tokenize(part)
+		words := make([]string, len(tokens))
+		for j, t := range tokens {
+			words[j] = t.term
+		}
+		// Odd-numbered parts sit between quotes
+		if i%2 == 1 && len(words) > 1 {
+			phrases = append(phrases, words)
+		}
+		terms = append(terms, words...)
+	}
+	slices.Sort(terms)
+	return slices.Compact(terms), phrases
+}
+
+// Search runs a full-text query ranked by BM25. Quoted phrases must appear
+// verbatim in a single field. Results are restricted by the filter and
+// capped at limit; the total number of matches is also returned.
+func (s *Service) Search(query string, f Filter, limit int) ([]SearchResult, int) {
+	d := s.data()
+	terms, phrases := parseSearchQuery(query)
+	if len(terms) == 0 {
+		return nil, 0
+	}
+	terms = d.index.expandTerms(terms)
+
+	var allowed map[int]bool
+	if !f.IsEmpty() {
+		indexes := d.filterIndexes(f)
+		allowed = make(map[int]bool, len(indexes))
+		for _, i := range indexes {
+			allowed[i] = true
+		}
+	}
+
+	n := float64(len(d.quotes))
+	scores := make(map[int]float64)
+	for _, term := range terms {
+		postings := d.index.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			if allowed != nil && !allowed[p.doc] {
+				continue
+			}
+			tf := float64(p.tf)
+			norm := 1 - bm25B + bm25B*float64(d.index.docLen[p.doc])/d.index.avgDocLen
+			scores[p.doc] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+
+	docs := make([]int, 0, len(scores))
+	for doc := range scores {
+		if d.matchesPhrases(doc, phrases) {
+			docs = append(docs, doc)
+		}
+	}
+	slices.SortFunc(docs, func(a, b int) int {
+		if scores[a] != scores[b] {
+			if scores[a] > scores[b] {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(d.quotes[a].ID, d.quotes[b].ID)
+	})
+
+	total := len(docs)
+	if limit > 0 && len(docs) > limit {
+		docs = docs[:limit]
+	}
+
+	results := make([]SearchResult, len(docs))
+	for i, doc := range docs {
+		results[i] = SearchResult{
+			Quote:      d.quotes[doc],
+			Score:      math.Round(scores[doc]*1000) / 1000,
+			Highlights: highlight(d.quotes[doc], terms),
+		}
+	}
+	return results, total
+}
+
+// expandTerms replaces query terms that never occur in the index with the
+// closest indexed terms by edit distance, so small typos still match
+func (idx *searchIndex) expandTerms(terms []string) []string {
+	var expanded []string
+	for _, term := range terms {
+		if len(idx.postings[term]) > 0 {
+			expanded = append(expanded, term)
+			continue
+		}
+
+		limit := maxEditDistance(term)
+		if limit == 0 {
+			continue
+		}
+		best := limit + 1
+		var closest []string
+		for candidate := range idx.postings {
+			d := levenshtein(term, candidate, limit)
+			if d < best {
+				best = d
+				closest = closest[:0]
+			}
+			if d == best && d <= limit {
+				closest = append(closest, candidate)
+			}
+		}
+		expanded = append(expanded, closest...)
+	}
+	slices.Sort(expanded)
+	return slices.Compact(expanded)
+}
+
+// matchesPhrases reports whether every phrase occurs in one of the quote's fields
+func (d *dataset) matchesPhrases(doc int, phrases [][]string) bool {
+	for _, phrase := range phrases {
+		found := false
+		for _, field := range searchFields(d.quotes[doc]) {
+			if containsPhrase(tokenize(field.value), phrase) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// containsPhrase reports whether the phrase occurs as consecutive tokens
+func containsPhrase(tokens []token, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		match := true
+		for j, word := range phrase {
+			if tokens[i+j].term != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight returns the spans of every token matching one of the terms
+func highlight(q Quote, terms []string) []Highlight {
+	highlights := []Highlight{}
+	for _, field := range searchFields(q) {
+		for _, t := range tokenize(field.value) {
+			if _, found := slices.BinarySearch(terms, t.term); found {
+				highlights = append(highlights, Highlight{Field: field.name, Start: t.start, End: t.end})
+			}
+		}
+	}
+	return highlights
+}
@@ -0,0 +1,162 @@
+package anime
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// newTestService builds a service over the given quotes
+func newTestService(t *testing.T, quotes ...Quote) *Service {
+	t.Helper()
+	data, err := json.Marshal(quotes)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	s, err := NewService(data)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	return s
+}
+
+var searchQuotes = []Quote{
+	{Anime: "Naruto", Character: "Pain", Quote: "Peace peace peace, that is all I want."},
+	{Anime: "Naruto", Character: "Jiraiya", Quote: "The day true peace comes, I will understand the world and its many people and their pain."},
+	{Anime: "Naruto", Character: "Itachi", Quote: "Peace is true only when no one is left to fight."},
+	{Anime: "One Piece", Character: "Luffy", Quote: "I am going to be king of the pirates and chase my dream."},
+	{Anime: "Bleach", Character: "Ichigo", Quote: "I fight because I want to protect the people around me, every single one of them."},
+	{Anime: "Amélie", Character: "Amélie", Quote: "Ça va, café crème"},
+	{Anime: "Kimi no Na wa", Character: "Taki", Quote: "僕の夢 dream"},
+}
+
+func TestSearchRanking(t *testing.T) {
+	s := newTestService(t, searchQuotes...)
+
+	tests := []struct {
+		query string
+		want  []string // characters in rank order
+	}{
+		// Term frequency beats a single mention in a long quote
+		{"peace", []string{"Pain", "Itachi", "Jiraiya"}},
+		// The rare term "pirates" outweighs "fight", found in two quotes, and
+		// the shorter of those two ranks first
+		{"pirates fight", []string{"Luffy", "Itachi", "Ichigo"}},
+		// Quoted phrases must appear verbatim
+		{`"true peace"`, []string{"Jiraiya"}},
+		{`"peace is true"`, []string{"Itachi"}},
+		{`"true peace" fight`, []string{"Jiraiya"}},
+		{`"peace true"`, nil},
+		// Character and anime names are indexed too
+		{"luffy", []string{"Luffy"}},
+		// Typos expand to the closest indexed words
+		{"peacce", []string{"Pain", "Itachi", "Jiraiya"}},
+		// Accents are folded on both sides
+		{"cafe", []string{"Amélie"}},
+		{"unknownword", nil},
+	}
+	for _, tt := range tests {
+		results, total := s.Search(tt.query, Filter{}, 0)
+		var got []string
+		for _, r := range results {
+			got = append(got, r.Quote.Character)
+		}
+		if !equalStrings(got, tt.want) || total != len(tt.want) {
+			t.Errorf("Search(%q) = %v (total %d), want %v", tt.query, got, total, tt.want)
+		}
+		for i := 1; i < len(results); i++ {
+			if results[i].Score > results[i-1].Score {
+				t.Errorf("Search(%q): scores not descending: %v", tt.query, results)
+			}
+		}
+	}
+}
+
+func TestSearchLimitAndFilter(t *testing.T) {
+	s := newTestService(t, searchQuotes...)
+
+	results, total := s.Search("peace", Filter{}, 2)
+	if len(results) != 2 || total != 3 {
+		t.Errorf("limit 2: got %d results, total %d; want 2 and 3", len(results), total)
+	}
+
+	results, total = s.Search("fight", Filter{Anime: []string{"Bleach"}}, 0)
+	if total != 1 || results[0].Quote.Character != "Ichigo" {
+		t.Errorf("filtered search: got %v", results)
+	}
+}
+
+func TestSearchEmptyQuery(t *testing.T) {
+	s := newTestService(t, searchQuotes...)
+	for _, query := range []string{"", "   ", `""`, "!?, ..."} {
+		if results, total := s.Search(query, Filter{}, 0); results != nil || total != 0 {
+			t.Errorf("Search(%q) = %v, %d; want no results", query, results, total)
+		}
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	s := newTestService(t, searchQuotes...)
+
+	tests := []struct {
+		query string
+		want  []Highlight
+	}{
+		// Offsets count runes, not bytes
+		{"cafe", []Highlight{{Field: "quote", Start: 7, End: 11}}},
+		{"creme va", []Highlight{{Field: "quote", Start: 3, End: 5}, {Field: "quote", Start: 12, End: 17}}},
+		{"dream taki", []Highlight{{Field: "quote", Start: 4, End: 9}, {Field: "character", Start: 0, End: 4}}},
+		{"amelie", []Highlight{{Field: "character", Start: 0, End: 6}, {Field: "anime", Start: 0, End: 6}}},
+	}
+	for _, tt := range tests {
+		results, _ := s.Search(tt.query, Filter{}, 1)
+		if len(results) != 1 {
+			t.Errorf("Search(%q): got %d results, want 1", tt.query, len(results))
+			continue
+		}
+		got := results[0].Highlights
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q) highlights = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Search(%q) highlights = %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		terms   []string
+		phrases int
+	}{
+		{"Peace", []string{"peace"}, 0},
+		{`"true peace" fight`, []string{"fight", "peace", "true"}, 1},
+		{`"single" word`, []string{"single", "word"}, 0},
+		{`"unterminated phrase`, []string{"phrase", "unterminated"}, 1},
+		{"Shippūden SHIPPUDEN", []string{"shippuden"}, 0},
+	}
+	for _, tt := range tests {
+		terms, phrases := parseSearchQuery(tt.query)
+		if !equalStrings(terms, tt.terms) || len(phrases) != tt.phrases {
+			t.Errorf("parseSearchQuery(%q) = %v, %v; want %v and %d phrases", tt.query, terms, phrases, tt.terms, tt.phrases)
+		}
+	}
+}
+
+// equalStrings reports whether two string slices hold the same values,
+// treating nil and empty as equal
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
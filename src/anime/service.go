@@ -0,0 +1,143 @@
+package anime
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// idLength is the number of hex characters kept from the content hash
+const idLength = 12
+
+// Quote represents a single anime quote
+type Quote struct {
+	ID        string `json:"id" yaml:"id,omitempty"`
+	Anime     string `json:"anime" yaml:"anime"`
+	Character string `json:"character" yaml:"character"`
+	Quote     string `json:"quote" yaml:"quote"`
+}
+
+// Service provides access to the anime quotes dataset. The embedded quotes
+// can be extended with quote packs from a directory (see LoadDir); the
+// indexes are rebuilt off to the side and swapped in atomically, so readers
+// always see a consistent dataset.
+type Service struct {
+	base    []Quote
+	current atomic.Pointer[dataset]
+
+	// Quote pack directory state, guarded by mu
+	mu       sync.Mutex
+	dir      string
+	dirState string
+}
+
+// dataset is an immutable snapshot of the quotes and their indexes
+type dataset struct {
+	quotes      []Quote
+	byID        map[string]int
+	byAnime     map[string][]int
+	byCharacter map[string][]int
+	index       *searchIndex
+	catalog     *catalog
+	daily       []int
+
+	animeSuggest     *prefixIndex
+	characterSuggest *prefixIndex
+}
+
+// NewService creates a new anime service from JSON data
+func NewService(data []byte) (*Service, error) {
+	quotes, err := ParseQuotes(data, FormatJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Service{base: quotes}
+	s.current.Store(newDataset(quotes))
+	return s, nil
+}
+
+// newDataset assigns IDs and builds every index for the given quotes
+func newDataset(quotes []Quote) *dataset {
+	d := &dataset{
+		quotes:      make([]Quote, len(quotes)),
+		byID:        make(map[string]int, len(quotes)),
+		byAnime:     make(map[string][]int),
+		byCharacter: make(map[string][]int),
+	}
+	copy(d.quotes, quotes)
+
+	for i := range d.quotes {
+		id := quoteID(d.quotes[i])
+		// Identical quotes hash to the same ID, so disambiguate by occurrence
+		for n := 2; ; n++ {
+			if _, exists := d.byID[id]; !exists {
+				break
+			}
+			id = fmt.Sprintf("%s-%d", quoteID(d.quotes[i]), n)
+		}
+		d.quotes[i].ID = id
+		d.byID[id] = i
+
+		animeKey := normalizeName(d.quotes[i].Anime)
+		d.byAnime[animeKey] = append(d.byAnime[animeKey], i)
+		characterKey := normalizeName(d.quotes[i].Character)
+		d.byCharacter[characterKey] = append(d.byCharacter[characterKey], i)
+	}
+
+	d.index = buildSearchIndex(d.quotes)
+	d.catalog = d.buildCatalog()
+	d.animeSuggest = newPrefixIndex(d.catalog.animeNames())
+	d.characterSuggest = newPrefixIndex(d.catalog.characterNames())
+	d.daily = d.buildDailyOrder()
+
+	return d
+}
+
+// data returns the current dataset snapshot
+func (s *Service) data() *dataset {
+	return s.current.Load()
+}
+
+// quoteID derives a stable ID from the quote content
+func quoteID(q Quote) string {
+	sum := sha256.Sum256([]byte(q.Anime + "\x00" + q.Character + "\x00" + q.Quote))
+	return hex.EncodeToString(sum[:])[:idLength]
+}
+
+// GetRandomQuote returns a random quote, or nil if no quotes are loaded
+func (s *Service) GetRandomQuote() *Quote {
+	d := s.data()
+	if len(d.quotes) == 0 {
+		return nil
+	}
+	q := d.quotes[rand.IntN(len(d.quotes))]
+	return &q
+}
+
+// GetQuoteByID returns the quote with the given ID
+func (s *Service) GetQuoteByID(id string) (*Quote, bool) {
+	d := s.data()
+	i, ok := d.byID[id]
+	if !ok {
+		return nil, false
+	}
+	q := d.quotes[i]
+	return &q, true
+}
+
+// GetAllQuotes returns a copy of all quotes in dataset order
+func (s *Service) GetAllQuotes() []Quote {
+	d := s.data()
+	quotes := make([]Quote, len(d.quotes))
+	copy(quotes, d.quotes)
+	return quotes
+}
+
+// GetTotalQuotes returns the total number of quotes
+func (s *Service) GetTotalQuotes() int {
+	return len(s.data().quotes)
+}
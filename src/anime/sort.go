@@ -0,0 +1,41 @@
+package anime
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortFields lists the fields accepted by SortQuotes
+var SortFields = []string{"id", "anime", "character"}
+
+// SortQuotes sorts quotes in place by the given field. A leading "-"
+// reverses the order. Ties are broken by ID so the order is stable.
+func SortQuotes(quotes []Quote, by string) error {
+	desc := strings.HasPrefix(by, "-")
+	field := strings.TrimPrefix(by, "-")
+
+	var key func(Quote) string
+	switch field {
+	case "id":
+		key = func(q Quote) string { return q.ID }
+	case "anime":
+		key = func(q Quote) string { return strings.ToLower(q.Anime) }
+	case "character":
+		key = func(q Quote) string { return strings.ToLower(q.Character) }
+	default:
+		return fmt.Errorf("invalid sort field %q (valid: %s)", field, strings.Join(SortFields, ", "))
+	}
+
+	slices.SortStableFunc(quotes, func(a, b Quote) int {
+		c := strings.Compare(key(a), key(b))
+		if c == 0 {
+			c = strings.Compare(a.ID, b.ID)
+		}
+		if desc {
+			return -c
+		}
+		return c
+	})
+	return nil
+}
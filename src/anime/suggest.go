@@ -0,0 +1,162 @@
+package anime
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// NameCount is an anime or character name with its number of quotes
+type NameCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// prefixIndex is a sorted list of name keys, one entry per word of each
+// name, so a binary search finds names by the start of any word
+type prefixIndex struct {
+	names   []NameCount
+	entries []prefixEntry
+}
+
+// prefixEntry points from a normalized name suffix to its name
+type prefixEntry struct {
+	key   string
+	name  int
+	first bool
+}
+
+// buildNameCounts groups quotes by normalized name and picks the most
+// common spelling of each group as its display name
+func buildNameCounts(quotes []Quote, index map[string][]int, field func(Quote) string) []NameCount {
+	names := make([]NameCount, 0, len(index))
+	for _, indexes := range index {
+		names = append(names, NameCount{
+			Name:  displayName(quotes, indexes, field),
+			Count: len(indexes),
+		})
+	}
+	slices.SortFunc(names, func(a, b NameCount) int {
+		return strings.Compare(normalizeName(a.Name), normalizeName(b.Name))
+	})
+	return names
+}
+
+// displayName returns the most frequent spelling of a field among the
+// given quotes, preferring the earliest on ties
+func displayName(quotes []Quote, indexes []int, field func(Quote) string) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, i := range indexes {
+		name := field(quotes[i])
+		counts[name]++
+		if counts[name] > counts[best] {
+			best = name
+		}
+	}
+	return best
+}
+
+// newPrefixIndex builds a prefix index over the given names
+func newPrefixIndex(names []NameCount) *prefixIndex {
+	p := &prefixIndex{names: names}
+	for i, n := range names {
+		words := strings.Fields(normalizeName(n.Name))
+		for w := range words {
+			p.entries = append(p.entries, prefixEntry{
+				key:   strings.Join(words[w:], " "),
+				name:  i,
+				first: w == 0,
+			})
+		}
+	}
+	sort.Slice(p.entries, func(a, b int) bool {
+		return p.entries[a].key < p.entries[b].key
+	})
+	return p
+}
+
+// lookup returns up to limit names with a word starting with prefix. Names
+// whose first word matches rank ahead of mid-name matches; within each
+// group names with more quotes rank first.
+func (p *prefixIndex) lookup(prefix string, limit int) []NameCount {
+	key := normalizeName(prefix)
+
+	matches := make(map[int]bool)
+	start := sort.Search(len(p.entries), func(i int) bool {
+		return p.entries[i].key >= key
+	})
+	for i := start; i < len(p.entries) && strings.HasPrefix(p.entries[i].key, key); i++ {
+		e := p.entries[i]
+		matches[e.name] = matches[e.name] || e.first
+	}
+
+	return rankNames(p.names, matches, limit)
+}
+
+// rankNames orders the matched names and applies the limit. The map value
+// records whether the match was at the start of the name.
+func rankNames(names []NameCount, matches map[int]bool, limit int) []NameCount {
+	ids := make([]int, 0, len(matches))
+	for id := range matches {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b int) int {
+		if matches[a] != matches[b] {
+			if matches[a] {
+				return -1
+			}
+			return 1
+		}
+		if names[a].Count != names[b].Count {
+			return names[b].Count - names[a].Count
+		}
+		return strings.Compare(names[a].Name, names[b].Name)
+	})
+
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	result := make([]NameCount, len(ids))
+	for i, id := range ids {
+		result[i] = names[id]
+	}
+	return result
+}
+
+// SuggestAnime returns anime names with a word starting with prefix
+func (s *Service) SuggestAnime(prefix string, limit int) []NameCount {
+	return s.data().animeSuggest.lookup(prefix, limit)
+}
+
+// SuggestCharacters returns character names with a word starting with
+// prefix. When anime is set, only characters from that anime are returned
+// and counts cover that anime alone.
+func (s *Service) SuggestCharacters(prefix, anime string, limit int) []NameCount {
+	d := s.data()
+	if strings.TrimSpace(anime) == "" {
+		return d.characterSuggest.lookup(prefix, limit)
+	}
+
+	// Group the anime's quotes by character and match prefixes directly;
+	// a single show has few enough characters that no index is needed
+	byCharacter := make(map[string][]int)
+	for _, i := range lookupIndexes(d.byAnime, []string{anime}) {
+		key := normalizeName(d.quotes[i].Character)
+		byCharacter[key] = append(byCharacter[key], i)
+	}
+	names := buildNameCounts(d.quotes, byCharacter, func(q Quote) string { return q.Character })
+
+	key := normalizeName(prefix)
+	matches := make(map[int]bool)
+	for i, n := range names {
+		words := strings.Fields(normalizeName(n.Name))
+		for w := range words {
+			if strings.HasPrefix(strings.Join(words[w:], " "), key) {
+				matches[i] = matches[i] || w == 0
+			}
+		}
+	}
+
+	return rankNames(names, matches, limit)
+}
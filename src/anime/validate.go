@@ -0,0 +1,326 @@
+package anime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Validation issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a single problem found while validating a quote dataset. Entry
+// is the 1-based position of the quote in the file, or 0 for file-level
+// problems.
+type Issue struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Entry    int    `json:"entry,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Report is the result of validating one quote dataset
+type Report struct {
+	File     string  `json:"file"`
+	Quotes   int     `json:"quotes"`
+	Errors   int     `json:"errors"`
+	Warnings int     `json:"warnings"`
+	Issues   []Issue `json:"issues"`
+}
+
+// add records an issue and updates the counters
+func (r *Report) add(severity, kind string, entry int, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{
+		Severity: severity,
+		Kind:     kind,
+		Entry:    entry,
+		Message:  fmt.Sprintf(format, args...),
+	})
+	if severity == SeverityError {
+		r.Errors++
+	} else {
+		r.Warnings++
+	}
+}
+
+// mojibakeMarkers are sequences typical of UTF-8 text decoded as Latin-1
+// or Windows-1252
+var mojibakeMarkers = []string{"Ã©", "Ã¨", "Ã¶", "Ã¼", "Ã±", "Ã ", "â€", "Â ", "Â·", "Ã¢", "�"}
+
+// ValidateFile validates a quote file, picking the format from its extension
+func ValidateFile(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format := FormatFromPath(path)
+	if format == "" {
+		return nil, fmt.Errorf("%s: unsupported file extension", path)
+	}
+	report := ValidateData(data, format)
+	report.File = path
+	return report, nil
+}
+
+// ValidateData validates raw quote data. It parses the data with the same
+// parser NewService and quote packs use, then checks the entries. When the
+// typed parse fails on a few bad entries, the remaining entries are still
+// checked so one run reports every problem.
+func ValidateData(data []byte, format string) *Report {
+	report := &Report{Issues: []Issue{}}
+
+	if !utf8.Valid(data) {
+		report.add(SeverityError, "encoding", 0, "file is not valid UTF-8")
+	}
+	errors := report.Errors
+	recovered, skip := checkSchema(report, data, format)
+	schemaErrors := report.Errors > errors
+
+	quotes, err := ParseQuotes(data, format)
+	if err != nil {
+		// The typed parser fails on the entries checkSchema has already
+		// reported, so only report its error when it found something else
+		if !schemaErrors {
+			report.add(SeverityError, "schema", 0, "%v", err)
+		}
+		if recovered == nil {
+			return report
+		}
+		quotes = recovered
+	} else {
+		skip = nil
+	}
+	report.Quotes = len(quotes) - len(skip)
+
+	checkQuotes(report, quotes, skip)
+	return report
+}
+
+// checkSchema decodes JSON and YAML generically to catch entries that are
+// not objects, fields of the wrong type, and missing or unknown fields,
+// which the typed parser rejects or silently tolerates. It returns the
+// entries it could read as quotes, along with the positions to skip.
+func checkSchema(report *Report, data []byte, format string) ([]Quote, map[int]bool) {
+	var entries []interface{}
+	switch format {
+	case FormatJSON:
+		if json.Unmarshal(data, &entries) != nil {
+			return nil, nil
+		}
+	case FormatYAML:
+		if yaml.Unmarshal(data, &entries) != nil {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	quotes := make([]Quote, len(entries))
+	skip := make(map[int]bool)
+	for i, entry := range entries {
+		obj, ok := entry.(map[string]interface{})
+		if !ok {
+			report.add(SeverityError, "schema", i+1, "entry is not an object")
+			skip[i] = true
+			continue
+		}
+
+		values := make(map[string]string)
+		for _, field := range []string{"anime", "character", "quote"} {
+			v, present := obj[field]
+			str, isString := v.(string)
+			switch {
+			case !present:
+				report.add(SeverityError, "schema", i+1, "missing field %q", field)
+				skip[i] = true
+			case !isString:
+				report.add(SeverityError, "schema", i+1, "field %q is not a string", field)
+				skip[i] = true
+			}
+			values[field] = str
+		}
+		quotes[i] = Quote{Anime: values["anime"], Character: values["character"], Quote: values["quote"]}
+
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			if !slices.Contains([]string{"id", "anime", "character", "quote"}, key) {
+				report.add(SeverityWarning, "schema", i+1, "unknown field %q", key)
+			}
+		}
+	}
+	return quotes, skip
+}
+
+// checkQuotes runs the content checks on parsed quotes, ignoring the
+// positions in skip
+func checkQuotes(report *Report, quotes []Quote, skip map[int]bool) {
+	for i, q := range quotes {
+		if skip[i] {
+			continue
+		}
+		for _, field := range []quoteField{{"anime", q.Anime}, {"character", q.Character}, {"quote", q.Quote}} {
+			checkField(report, i+1, field)
+		}
+	}
+
+	checkDuplicates(report, quotes, skip)
+	checkNameSpellings(report, quotes, skip, "anime", func(q Quote) string { return q.Anime })
+	checkNameSpellings(report, quotes, skip, "character", func(q Quote) string { return q.Character })
+	checkCharacterTypos(report, quotes, skip)
+}
+
+// checkField reports empty values, stray whitespace and encoding problems
+func checkField(report *Report, entry int, field quoteField) {
+	if strings.TrimSpace(field.value) == "" {
+		report.add(SeverityError, "empty_field", entry, "%s is empty", field.name)
+		return
+	}
+	if strings.TrimSpace(field.value) != field.value || strings.Contains(field.value, "  ") {
+		report.add(SeverityWarning, "whitespace", entry, "%s has leading, trailing or repeated spaces", field.name)
+	}
+
+	for _, marker := range mojibakeMarkers {
+		if strings.Contains(field.value, marker) {
+			report.add(SeverityWarning, "encoding", entry, "%s looks like mojibake (contains %q)", field.name, marker)
+			break
+		}
+	}
+
+	straight := strings.ContainsAny(field.value, `'"`)
+	curly := strings.ContainsAny(field.value, "‘’“”")
+	if straight && curly {
+		report.add(SeverityWarning, "encoding", entry, "%s mixes straight and curly quotes", field.name)
+	}
+}
+
+// checkDuplicates reports exact duplicates and quotes whose text only
+// differs by case, punctuation or a few characters
+func checkDuplicates(report *Report, quotes []Quote, skip map[int]bool) {
+	exact := make(map[string]int)
+	normalized := make(map[string]int)
+	buckets := make(map[string][]int)
+
+	for i, q := range quotes {
+		if skip[i] || strings.TrimSpace(q.Quote) == "" {
+			continue
+		}
+
+		id := quoteID(q)
+		if first, ok := exact[id]; ok {
+			report.add(SeverityError, "duplicate", i+1, "duplicate of entry %d", first+1)
+			continue
+		}
+		exact[id] = i
+
+		text := normalizeName(q.Quote)
+		if first, ok := normalized[text]; ok {
+			report.add(SeverityWarning, "near_duplicate", i+1, "same text as entry %d (%s / %s)",
+				first+1, quotes[first].Character, quotes[first].Anime)
+			continue
+		}
+		normalized[text] = i
+
+		// Only compare quotes that open with the same words, which keeps
+		// the edit distance checks far from quadratic
+		words := strings.Fields(text)
+		bucket := strings.Join(words[:min(3, len(words))], " ")
+		for _, j := range buckets[bucket] {
+			other := normalizeName(quotes[j].Quote)
+			limit := max(1, len([]rune(text))/10)
+			if levenshtein(text, other, limit) <= limit {
+				report.add(SeverityWarning, "near_duplicate", i+1, "nearly identical to entry %d", j+1)
+				break
+			}
+		}
+		buckets[bucket] = append(buckets[bucket], i)
+	}
+}
+
+// checkNameSpellings reports names that normalize to the same key but are
+// written differently, e.g. "Naruto Shippuden" and "Naruto Shippuuden"
+func checkNameSpellings(report *Report, quotes []Quote, skip map[int]bool, kind string, field func(Quote) string) {
+	spellings := make(map[string][]string)
+	firstSeen := make(map[string]int)
+
+	for i, q := range quotes {
+		name := field(q)
+		key := normalizeName(name)
+		if skip[i] || key == "" {
+			continue
+		}
+		if !slices.Contains(spellings[key], name) {
+			if len(spellings[key]) > 0 {
+				report.add(SeverityWarning, "inconsistent_name", i+1, "%s %q is also written %q (entry %d)",
+					kind, name, spellings[key][0], firstSeen[key]+1)
+			} else {
+				firstSeen[key] = i
+			}
+			spellings[key] = append(spellings[key], name)
+		}
+	}
+}
+
+// checkCharacterTypos reports character names within one anime that are a
+// single edit apart, which usually means one of them is misspelled
+func checkCharacterTypos(report *Report, quotes []Quote, skip map[int]bool) {
+	type character struct {
+		key   string
+		name  string
+		entry int
+	}
+	byAnime := make(map[string][]character)
+
+	for i, q := range quotes {
+		animeKey := normalizeName(q.Anime)
+		key := normalizeName(q.Character)
+		if skip[i] || key == "" || len([]rune(key)) < 5 {
+			continue
+		}
+
+		known := false
+		for _, c := range byAnime[animeKey] {
+			if c.key == key {
+				known = true
+				break
+			}
+		}
+		if known {
+			continue
+		}
+
+		for _, c := range byAnime[animeKey] {
+			if levenshtein(key, c.key, 1) <= 1 {
+				report.add(SeverityWarning, "inconsistent_name", i+1, "character %q looks like a misspelling of %q (entry %d)",
+					q.Character, c.name, c.entry)
+				break
+			}
+		}
+		byAnime[animeKey] = append(byAnime[animeKey], character{key: key, name: q.Character, entry: i + 1})
+	}
+}
+
+// String formats the report for humans
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %d quotes, %d errors, %d warnings\n", r.File, r.Quotes, r.Errors, r.Warnings)
+	for _, issue := range r.Issues {
+		location := "file"
+		if issue.Entry > 0 {
+			location = fmt.Sprintf("entry %d", issue.Entry)
+		}
+		fmt.Fprintf(&sb, "  %-7s %-10s [%s] %s\n", issue.Severity, location, issue.Kind, issue.Message)
+	}
+	return sb.String()
+}
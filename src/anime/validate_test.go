@@ -0,0 +1,66 @@
+package anime
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// issueKeys summarizes a report's issues as "severity kind entry"
+func issueKeys(r *Report) []string {
+	var keys []string
+	for _, issue := range r.Issues {
+		keys = append(keys, fmt.Sprintf("%s %s %d", issue.Severity, issue.Kind, issue.Entry))
+	}
+	return keys
+}
+
+func TestValidateFile(t *testing.T) {
+	tests := []struct {
+		file   string
+		quotes int
+		want   []string
+	}{
+		{"valid.json", 2, nil},
+		{"valid.csv", 1, nil},
+		// Each bad entry is reported once, without a second file-level
+		// error from the typed parse
+		{"schema.json", 2, []string{
+			"error schema 2",
+			"error schema 3",
+			"error schema 4",
+			"warning schema 5",
+		}},
+		{"syntax.json", 0, []string{"error schema 0"}},
+		{"columns.csv", 0, []string{"error schema 0"}},
+		{"content.yaml", 6, []string{
+			"warning whitespace 5",
+			"error empty_field 5",
+			"warning encoding 6",
+			"warning encoding 6",
+			"warning near_duplicate 2",
+			"error duplicate 3",
+			"warning inconsistent_name 2",
+			"warning inconsistent_name 4",
+		}},
+	}
+	for _, tt := range tests {
+		report, err := ValidateFile(filepath.Join("testdata", "validate", tt.file))
+		if err != nil {
+			t.Errorf("%s: %v", tt.file, err)
+			continue
+		}
+		got := issueKeys(report)
+		if report.Quotes != tt.quotes || !equalStrings(got, tt.want) {
+			t.Errorf("%s: %d quotes, issues %q; want %d and %q", tt.file, report.Quotes, got, tt.quotes, tt.want)
+		}
+	}
+}
+
+func TestValidateDataInvalidUTF8(t *testing.T) {
+	data := []byte("[{\"anime\": \"Naruto\", \"character\": \"Naruto \xff\", \"quote\": \"Believe it!\"}]")
+	report := ValidateData(data, "json")
+	if got := issueKeys(report); len(got) == 0 || got[0] != "error encoding 0" {
+		t.Errorf("invalid UTF-8: issues %q, want a file-level encoding error first", got)
+	}
+}
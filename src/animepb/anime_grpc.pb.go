@@ -0,0 +1,253 @@
+// Anime Quotes gRPC API. Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: anime.proto
+
+package animepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AnimeQuotes_GetQuote_FullMethodName   = "/anime.v1.AnimeQuotes/GetQuote"
+	AnimeQuotes_Random_FullMethodName     = "/anime.v1.AnimeQuotes/Random"
+	AnimeQuotes_Search_FullMethodName     = "/anime.v1.AnimeQuotes/Search"
+	AnimeQuotes_ListQuotes_FullMethodName = "/anime.v1.AnimeQuotes/ListQuotes"
+)
+
+// AnimeQuotesClient is the client API for AnimeQuotes service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AnimeQuotes serves the same quotes as the /api/v1 REST endpoints
+type AnimeQuotesClient interface {
+	// GetQuote returns a quote by ID, or NOT_FOUND
+	GetQuote(ctx context.Context, in *GetQuoteRequest, opts ...grpc.CallOption) (*Quote, error)
+	// Random returns a random quote matching the filter, or NOT_FOUND
+	Random(ctx context.Context, in *RandomRequest, opts ...grpc.CallOption) (*Quote, error)
+	// Search ranks quotes by relevance to a full-text query
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// ListQuotes streams every quote matching the filter
+	ListQuotes(ctx context.Context, in *ListQuotesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Quote], error)
+}
+
+type animeQuotesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAnimeQuotesClient(cc grpc.ClientConnInterface) AnimeQuotesClient {
+	return &animeQuotesClient{cc}
+}
+
+func (c *animeQuotesClient) GetQuote(ctx context.Context, in *GetQuoteRequest, opts ...grpc.CallOption) (*Quote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quote)
+	err := c.cc.Invoke(ctx, AnimeQuotes_GetQuote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *animeQuotesClient) Random(ctx context.Context, in *RandomRequest, opts ...grpc.CallOption) (*Quote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quote)
+	err := c.cc.Invoke(ctx, AnimeQuotes_Random_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *animeQuotesClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, AnimeQuotes_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *animeQuotesClient) ListQuotes(ctx context.Context, in *ListQuotesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Quote], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AnimeQuotes_ServiceDesc.Streams[0], AnimeQuotes_ListQuotes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListQuotesRequest, Quote]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AnimeQuotes_ListQuotesClient = grpc.ServerStreamingClient[Quote]
+
+// AnimeQuotesServer is the server API for AnimeQuotes service.
+// All implementations must embed UnimplementedAnimeQuotesServer
+// for forward compatibility.
+//
+// AnimeQuotes serves the same quotes as the /api/v1 REST endpoints
+type AnimeQuotesServer interface {
+	// GetQuote returns a quote by ID, or NOT_FOUND
+	GetQuote(context.Context, *GetQuoteRequest) (*Quote, error)
+	// Random returns a random quote matching the filter, or NOT_FOUND
+	Random(context.Context, *RandomRequest) (*Quote, error)
+	// Search ranks quotes by relevance to a full-text query
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// ListQuotes streams every quote matching the filter
+	ListQuotes(*ListQuotesRequest, grpc.ServerStreamingServer[Quote]) error
+	mustEmbedUnimplementedAnimeQuotesServer()
+}
+
+// UnimplementedAnimeQuotesServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAnimeQuotesServer struct{}
+
+func (UnimplementedAnimeQuotesServer) GetQuote(context.Context, *GetQuoteRequest) (*Quote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuote not implemented")
+}
+func (UnimplementedAnimeQuotesServer) Random(context.Context, *RandomRequest) (*Quote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Random not implemented")
+}
+func (UnimplementedAnimeQuotesServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedAnimeQuotesServer) ListQuotes(*ListQuotesRequest, grpc.ServerStreamingServer[Quote]) error {
+	return status.Errorf(codes.Unimplemented, "method ListQuotes not implemented")
+}
+func (UnimplementedAnimeQuotesServer) mustEmbedUnimplementedAnimeQuotesServer() {}
+func (UnimplementedAnimeQuotesServer) testEmbeddedByValue()                     {}
+
+// UnsafeAnimeQuotesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AnimeQuotesServer will
+// result in compilation errors.
+type UnsafeAnimeQuotesServer interface {
+	mustEmbedUnimplementedAnimeQuotesServer()
+}
+
+func RegisterAnimeQuotesServer(s grpc.ServiceRegistrar, srv AnimeQuotesServer) {
+	// If the following call pancis, it indicates UnimplementedAnimeQuotesServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AnimeQuotes_ServiceDesc, srv)
+}
+
+func _AnimeQuotes_GetQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnimeQuotesServer).GetQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnimeQuotes_GetQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnimeQuotesServer).GetQuote(ctx, req.(*GetQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnimeQuotes_Random_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RandomRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnimeQuotesServer).Random(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnimeQuotes_Random_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnimeQuotesServer).Random(ctx, req.(*RandomRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnimeQuotes_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnimeQuotesServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnimeQuotes_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnimeQuotesServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnimeQuotes_ListQuotes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListQuotesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AnimeQuotesServer).ListQuotes(m, &grpc.GenericServerStream[ListQuotesRequest, Quote]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AnimeQuotes_ListQuotesServer = grpc.ServerStreamingServer[Quote]
+
+// AnimeQuotes_ServiceDesc is the grpc.ServiceDesc for AnimeQuotes service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AnimeQuotes_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "anime.v1.AnimeQuotes",
+	HandlerType: (*AnimeQuotesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetQuote",
+			Handler:    _AnimeQuotes_GetQuote_Handler,
+		},
+		{
+			MethodName: "Random",
+			Handler:    _AnimeQuotes_Random_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _AnimeQuotes_Search_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListQuotes",
+			Handler:       _AnimeQuotes_ListQuotes_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "anime.proto",
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+	"time"
+)
+
+// SessionLifetime is how long an admin login lasts
+const SessionLifetime = 12 * time.Hour
+
+// Sessions holds the admin login sessions. A session stands in for the
+// token used to sign in, so the token itself never goes into a cookie.
+// Sessions are kept in memory, by hash like tokens, and end when they
+// expire, on logout or when the server restarts.
+type Sessions struct {
+	lifetime time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]session
+}
+
+// session is the token a session was created for and when it ends
+type session struct {
+	tokenID string
+	expires time.Time
+}
+
+// NewSessions returns an empty session store whose sessions last lifetime
+func NewSessions(lifetime time.Duration) *Sessions {
+	return &Sessions{lifetime: lifetime, sessions: make(map[string]session)}
+}
+
+// Create starts a session for the token with the given ID and returns its
+// secret and expiry. Expired sessions are dropped on the way.
+func (s *Sessions) Create(tokenID string) (string, time.Time, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", time.Time{}, err
+	}
+	plaintext := base64.RawURLEncoding.EncodeToString(secret)
+	now := time.Now()
+	expires := now.Add(s.lifetime)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, sess := range s.sessions {
+		if !now.Before(sess.expires) {
+			delete(s.sessions, hash)
+		}
+	}
+	s.sessions[hashToken(plaintext)] = session{tokenID: tokenID, expires: expires}
+	return plaintext, expires, nil
+}
+
+// Lookup returns the token ID of a live session
+func (s *Sessions) Lookup(secret string) (string, bool) {
+	if secret == "" {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hash := hashToken(secret)
+	sess, ok := s.sessions[hash]
+	if !ok {
+		return "", false
+	}
+	if !time.Now().Before(sess.expires) {
+		delete(s.sessions, hash)
+		return "", false
+	}
+	return sess.tokenID, true
+}
+
+// Revoke ends a session
+func (s *Sessions) Revoke(secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, hashToken(secret))
+}
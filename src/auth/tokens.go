@@ -0,0 +1,237 @@
+// Package auth manages the API tokens that protect the admin pages and the
+// login sessions made with them. Only SHA-256 hashes of the tokens are
+// stored, in tokens.yml in the config directory.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scopes a token can carry
+const (
+	// ScopeAdminRead allows viewing the admin pages and settings
+	ScopeAdminRead = "admin:read"
+	// ScopeAdminWrite allows changing, importing and resetting settings
+	ScopeAdminWrite = "admin:write"
+)
+
+// Scopes lists every scope
+var Scopes = []string{ScopeAdminRead, ScopeAdminWrite}
+
+// tokenPrefix marks secrets issued by this server, so they are easy to
+// spot in logs and secret scanners
+const tokenPrefix = "anime_"
+
+// Token is a stored API token
+type Token struct {
+	ID        string    `yaml:"id" json:"id"`
+	Name      string    `yaml:"name" json:"name"`
+	Hash      string    `yaml:"hash" json:"-"`
+	Scopes    []string  `yaml:"scopes" json:"scopes"`
+	CreatedAt time.Time `yaml:"created_at" json:"createdAt"`
+}
+
+// HasScope reports whether the token carries scope
+func (t Token) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// Path returns the token file in a config directory
+func Path(configDir string) string {
+	return filepath.Join(configDir, "tokens.yml")
+}
+
+// Store reads and writes the token file. Changes made by another process,
+// such as `anime --token create` while the server runs, are picked up on
+// the next lookup.
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	tokens  []Token
+	modTime time.Time
+	size    int64
+}
+
+// NewStore returns a store for the token file at path. The file does not
+// have to exist yet.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// refresh reloads the file if it changed since it was last read
+func (s *Store) refresh() error {
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		s.tokens, s.modTime, s.size = nil, time.Time{}, 0
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size && s.tokens != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	var file struct {
+		Tokens []Token `yaml:"tokens"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	s.tokens = file.Tokens
+	if s.tokens == nil {
+		s.tokens = []Token{}
+	}
+	s.modTime, s.size = info.ModTime(), info.Size()
+	return nil
+}
+
+// save writes the tokens, readable by the owner only
+func (s *Store) save() error {
+	data, err := yaml.Marshal(map[string][]Token{"tokens": s.tokens})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".tokens.yml.*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// List returns the stored tokens
+func (s *Store) List() ([]Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	return slices.Clone(s.tokens), nil
+}
+
+// Create issues a token with the given scopes. The returned secret is only
+// available now; the store keeps its hash.
+func (s *Store) Create(name string, scopes []string) (string, Token, error) {
+	if len(scopes) == 0 {
+		return "", Token{}, fmt.Errorf("a token needs at least one scope (%s)", strings.Join(Scopes, ", "))
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return "", Token{}, fmt.Errorf("unknown scope %q (valid: %s)", scope, strings.Join(Scopes, ", "))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return "", Token{}, err
+	}
+
+	id := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", Token{}, err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", Token{}, err
+	}
+	plaintext := tokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	token := Token{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Hash:      hashToken(plaintext),
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(scopes))),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	s.tokens = append(s.tokens, token)
+	if err := s.save(); err != nil {
+		return "", Token{}, err
+	}
+	return plaintext, token, nil
+}
+
+// Revoke deletes the token with the given ID
+func (s *Store) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(s.tokens, func(t Token) bool { return t.ID == id })
+	if i < 0 {
+		return fmt.Errorf("no token with ID %q", id)
+	}
+	s.tokens = slices.Delete(s.tokens, i, i+1)
+	return s.save()
+}
+
+// Get returns the token with the given ID
+func (s *Store) Get(id string) (Token, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return Token{}, false, err
+	}
+
+	i := slices.IndexFunc(s.tokens, func(t Token) bool { return t.ID == id })
+	if i < 0 {
+		return Token{}, false, nil
+	}
+	return s.tokens[i], true, nil
+}
+
+// Authenticate returns the token matching secret
+func (s *Store) Authenticate(secret string) (Token, bool, error) {
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		return Token{}, false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return Token{}, false, err
+	}
+
+	hash := []byte(hashToken(secret))
+	for _, token := range s.tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(token.Hash)) == 1 {
+			return token, true, nil
+		}
+	}
+	return Token{}, false, nil
+}
+
+// hashToken returns the hex SHA-256 of a token. Tokens are long random
+// strings, so a fast hash is enough.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
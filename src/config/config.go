@@ -0,0 +1,237 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config represents the server configuration (server.yml)
+type Config struct {
+	Server      ServerConfig      `yaml:"server"`
+	WebUI       WebUIConfig       `yaml:"web-ui"`
+	WebRobots   WebRobotsConfig   `yaml:"web-robots"`
+	WebSecurity WebSecurityConfig `yaml:"web-security"`
+}
+
+// ServerConfig holds the listener settings
+type ServerConfig struct {
+	Port    string       `yaml:"port"`
+	FQDN    string       `yaml:"fqdn"`
+	Address string       `yaml:"address"`
+	GRPC    GRPCConfig   `yaml:"grpc"`
+	Limits  LimitsConfig `yaml:"limits"`
+	// TrustedProxies lists the IPs and CIDRs of reverse proxies whose
+	// Forwarded and X-Forwarded-For headers are believed
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// TrustedProxyPrefixes parses TrustedProxies. A bare IP matches only
+// itself.
+func (s ServerConfig) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(s.TrustedProxies))
+	for _, entry := range s.TrustedProxies {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		ip, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("server.trusted_proxies: %q is not an IP address or CIDR", entry)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(ip, ip.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// GRPCConfig controls the gRPC service, which is off by default. With no
+// port it shares the HTTP listener; otherwise it listens on its own port.
+// Reflection is only registered when asked for.
+type GRPCConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Port       string `yaml:"port"`
+	Reflection bool   `yaml:"reflection"`
+}
+
+// LimitsConfig holds the rate, size and connection limits. Rates are
+// requests per second per client IP.
+type LimitsConfig struct {
+	GlobalRPS     int           `yaml:"global_rps"`
+	APIRPS        int           `yaml:"api_rps"`
+	MaxBodyMB     int           `yaml:"max_body_mb"`
+	MaxHeaderKB   int           `yaml:"max_header_kb"`
+	MaxConcurrent int           `yaml:"max_concurrent"`
+	MaxStreams    int           `yaml:"max_streams"`
+	ReadTimeout   time.Duration `yaml:"read_timeout"`
+	WriteTimeout  time.Duration `yaml:"write_timeout"`
+	IdleTimeout   time.Duration `yaml:"idle_timeout"`
+}
+
+// WebUIConfig holds the web UI settings
+type WebUIConfig struct {
+	Theme string `yaml:"theme"`
+}
+
+// WebRobotsConfig lists the paths published in robots.txt
+type WebRobotsConfig struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// WebSecurityConfig holds the security contact and CORS settings
+type WebSecurityConfig struct {
+	Admin string `yaml:"admin"`
+	CORS  string `yaml:"cors"`
+}
+
+// Default returns the configuration used when no file exists
+func Default() *Config {
+	cfg := &Config{}
+	cfg.Server.Address = "0.0.0.0"
+	cfg.Server.Limits = LimitsConfig{
+		GlobalRPS:     100,
+		APIRPS:        50,
+		MaxBodyMB:     10,
+		MaxHeaderKB:   1024,
+		MaxConcurrent: 1000,
+		MaxStreams:    100,
+		ReadTimeout:   10 * time.Second,
+		WriteTimeout:  10 * time.Second,
+		IdleTimeout:   120 * time.Second,
+	}
+	cfg.WebUI.Theme = "dark"
+	cfg.WebRobots.Allow = []string{"/", "/api"}
+	cfg.WebRobots.Deny = []string{"/debug"}
+	cfg.WebSecurity.CORS = "*"
+	return cfg
+}
+
+// Load reads the configuration file. Settings missing from the file keep
+// their defaults, and a missing file yields the defaults.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration in %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the configuration to path. The file is replaced atomically,
+// so a failed write leaves the previous configuration in place.
+func (c *Config) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Clone returns a deep copy of the configuration
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.Server.TrustedProxies = slices.Clone(c.Server.TrustedProxies)
+	clone.WebRobots.Allow = slices.Clone(c.WebRobots.Allow)
+	clone.WebRobots.Deny = slices.Clone(c.WebRobots.Deny)
+	return &clone
+}
+
+// Validate checks the settings that would otherwise fail at startup
+func (c *Config) Validate() error {
+	if err := validatePort("server.port", c.Server.Port); err != nil {
+		return err
+	}
+	if err := validatePort("server.grpc.port", c.Server.GRPC.Port); err != nil {
+		return err
+	}
+	if c.Server.GRPC.Port != "" && c.Server.GRPC.Port == c.Server.Port {
+		return fmt.Errorf("server.grpc.port must differ from server.port (leave it empty to share the HTTP port)")
+	}
+	if _, err := c.Server.TrustedProxyPrefixes(); err != nil {
+		return err
+	}
+	return c.Server.Limits.validate()
+}
+
+// validate checks that every limit is set and that live streams leave room
+// for regular requests
+func (l LimitsConfig) validate() error {
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{"server.limits.global_rps", l.GlobalRPS},
+		{"server.limits.api_rps", l.APIRPS},
+		{"server.limits.max_body_mb", l.MaxBodyMB},
+		{"server.limits.max_header_kb", l.MaxHeaderKB},
+		{"server.limits.max_concurrent", l.MaxConcurrent},
+		{"server.limits.max_streams", l.MaxStreams},
+	}
+	for _, count := range counts {
+		if count.value < 1 {
+			return fmt.Errorf("%s must be at least 1, got %d", count.name, count.value)
+		}
+	}
+	if l.MaxStreams >= l.MaxConcurrent {
+		return fmt.Errorf("server.limits.max_streams (%d) must be below server.limits.max_concurrent (%d)", l.MaxStreams, l.MaxConcurrent)
+	}
+
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.limits.read_timeout", l.ReadTimeout},
+		{"server.limits.write_timeout", l.WriteTimeout},
+		{"server.limits.idle_timeout", l.IdleTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value < time.Second {
+			return fmt.Errorf("%s must be at least 1s, got %v", timeout.name, timeout.value)
+		}
+	}
+	return nil
+}
+
+// validatePort checks an optional port number
+func validatePort(name, port string) error {
+	if port == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("%s must be a port number between 1 and 65535, got %q", name, port)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr string
+	}{
+		{"defaults", func(c *Config) {}, ""},
+		{"smallest limits", func(c *Config) {
+			c.Server.Limits = LimitsConfig{GlobalRPS: 1, APIRPS: 1, MaxBodyMB: 1, MaxHeaderKB: 1, MaxConcurrent: 2, MaxStreams: 1,
+				ReadTimeout: time.Second, WriteTimeout: time.Second, IdleTimeout: time.Second}
+		}, ""},
+		{"zero global rate", func(c *Config) { c.Server.Limits.GlobalRPS = 0 }, "server.limits.global_rps must be at least 1"},
+		{"negative api rate", func(c *Config) { c.Server.Limits.APIRPS = -5 }, "server.limits.api_rps must be at least 1"},
+		{"zero body size", func(c *Config) { c.Server.Limits.MaxBodyMB = 0 }, "server.limits.max_body_mb"},
+		{"zero header size", func(c *Config) { c.Server.Limits.MaxHeaderKB = 0 }, "server.limits.max_header_kb"},
+		{"zero concurrency", func(c *Config) { c.Server.Limits.MaxConcurrent = 0 }, "server.limits.max_concurrent"},
+		{"zero streams", func(c *Config) { c.Server.Limits.MaxStreams = 0 }, "server.limits.max_streams must be at least 1"},
+		{"streams equal to concurrency", func(c *Config) { c.Server.Limits.MaxStreams = c.Server.Limits.MaxConcurrent }, "must be below server.limits.max_concurrent"},
+		{"short read timeout", func(c *Config) { c.Server.Limits.ReadTimeout = 999 * time.Millisecond }, "server.limits.read_timeout must be at least 1s"},
+		{"zero write timeout", func(c *Config) { c.Server.Limits.WriteTimeout = 0 }, "server.limits.write_timeout"},
+		{"zero idle timeout", func(c *Config) { c.Server.Limits.IdleTimeout = 0 }, "server.limits.idle_timeout"},
+		{"port", func(c *Config) { c.Server.Port = "8080" }, ""},
+		{"port out of range", func(c *Config) { c.Server.Port = "65536" }, "server.port must be a port number"},
+		{"port zero", func(c *Config) { c.Server.Port = "0" }, "server.port must be a port number"},
+		{"port not a number", func(c *Config) { c.Server.Port = "http" }, "server.port must be a port number"},
+		{"gRPC port out of range", func(c *Config) { c.Server.GRPC.Port = "70000" }, "server.grpc.port must be a port number"},
+		{"gRPC port equal to HTTP port", func(c *Config) { c.Server.Port, c.Server.GRPC.Port = "8080", "8080" }, "must differ from server.port"},
+		{"trusted proxies", func(c *Config) { c.Server.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.10", "::1"} }, ""},
+		{"bad trusted proxy", func(c *Config) { c.Server.TrustedProxies = []string{"10.0.0.0/33"} }, "server.trusted_proxies"},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		tt.change(cfg)
+		err := cfg.Validate()
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: error %v, want one mentioning %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSetValidatesType(t *testing.T) {
+	tests := []struct {
+		key, value string
+		wantErr    string
+	}{
+		{"server.limits.api_rps", "20", ""},
+		{"server.limits.api_rps", "twenty", "whole number"},
+		{"server.limits.read_timeout", "30s", ""},
+		{"server.limits.read_timeout", "30", "duration"},
+		{"server.grpc.enabled", "yes", "true or false"},
+		{"web-ui.theme", "light", ""},
+		{"web-ui.theme", "blue", "must be one of"},
+		{"server.nothing", "1", "unknown setting"},
+	}
+	for _, tt := range tests {
+		err := Default().Set(tt.key, tt.value)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s=%s: unexpected error %v", tt.key, tt.value, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s=%s: error %v, want one mentioning %q", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+}
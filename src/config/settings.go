@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Setting describes a configuration value that can be read and changed at
+// runtime, e.g. from the admin settings page. Values are exchanged as
+// strings; lists are comma-separated.
+type Setting struct {
+	Key             string   `json:"key"`
+	Category        string   `json:"category"`
+	Type            string   `json:"type"`
+	Description     string   `json:"description"`
+	Options         []string `json:"options,omitempty"`
+	RequiresRestart bool     `json:"requiresRestart"`
+
+	get func(c *Config) string
+	set func(c *Config, value string) error
+}
+
+// Setting types
+const (
+	TypeString = "string"
+	TypeBool   = "bool"
+	TypeList   = "list"
+	TypeEnum   = "enum"
+	TypeInt    = "int"
+	// TypeDuration values are Go durations, e.g. 30s or 2m
+	TypeDuration = "duration"
+)
+
+// settings lists the editable settings in display order. Keys follow the
+// layout of server.yml.
+var settings = []Setting{
+	{
+		Key: "server.port", Category: "server", Type: TypeString, RequiresRestart: true,
+		Description: "HTTP port (empty uses 8080 or the PORT environment variable)",
+		get:         func(c *Config) string { return c.Server.Port },
+		set:         func(c *Config, v string) error { c.Server.Port = v; return nil },
+	},
+	{
+		Key: "server.address", Category: "server", Type: TypeString, RequiresRestart: true,
+		Description: "Address to listen on (0.0.0.0 for all IPv4, :: for all addresses)",
+		get:         func(c *Config) string { return c.Server.Address },
+		set:         func(c *Config, v string) error { c.Server.Address = v; return nil },
+	},
+	{
+		Key: "server.fqdn", Category: "server", Type: TypeString,
+		Description: "Public host name used in feed and permalink URLs",
+		get:         func(c *Config) string { return c.Server.FQDN },
+		set:         func(c *Config, v string) error { c.Server.FQDN = v; return nil },
+	},
+	{
+		Key: "server.trusted_proxies", Category: "server", Type: TypeList,
+		Description: "Reverse proxy IPs or CIDRs whose Forwarded and X-Forwarded-For headers name the client",
+		get:         func(c *Config) string { return strings.Join(c.Server.TrustedProxies, ", ") },
+		set:         func(c *Config, v string) error { c.Server.TrustedProxies = splitList(v); return nil },
+	},
+	grpcSetting("server.grpc.enabled", "Serve the gRPC API",
+		func(g *GRPCConfig) *bool { return &g.Enabled }),
+	{
+		Key: "server.grpc.port", Category: "grpc", Type: TypeString, RequiresRestart: true,
+		Description: "Dedicated gRPC port (empty shares the HTTP port)",
+		get:         func(c *Config) string { return c.Server.GRPC.Port },
+		set:         func(c *Config, v string) error { c.Server.GRPC.Port = v; return nil },
+	},
+	grpcSetting("server.grpc.reflection", "Register gRPC server reflection, which lists the services to any client",
+		func(g *GRPCConfig) *bool { return &g.Reflection }),
+	intSetting("server.limits.global_rps", "Requests per second per client across all endpoints", false,
+		func(l *LimitsConfig) *int { return &l.GlobalRPS }),
+	intSetting("server.limits.api_rps", "Requests per second per client to the API", false,
+		func(l *LimitsConfig) *int { return &l.APIRPS }),
+	intSetting("server.limits.max_body_mb", "Largest request body in MB", false,
+		func(l *LimitsConfig) *int { return &l.MaxBodyMB }),
+	intSetting("server.limits.max_header_kb", "Largest request header block in KB", true,
+		func(l *LimitsConfig) *int { return &l.MaxHeaderKB }),
+	intSetting("server.limits.max_concurrent", "Requests handled at once; more are refused with 503", false,
+		func(l *LimitsConfig) *int { return &l.MaxConcurrent }),
+	intSetting("server.limits.max_streams", "Open live streams (SSE and WebSocket) at once", false,
+		func(l *LimitsConfig) *int { return &l.MaxStreams }),
+	durationSetting("server.limits.read_timeout", "Time allowed to read a request", false,
+		func(l *LimitsConfig) *time.Duration { return &l.ReadTimeout }),
+	durationSetting("server.limits.write_timeout", "Time allowed to write a response (live streams extend it)", false,
+		func(l *LimitsConfig) *time.Duration { return &l.WriteTimeout }),
+	durationSetting("server.limits.idle_timeout", "How long idle keep-alive connections stay open", true,
+		func(l *LimitsConfig) *time.Duration { return &l.IdleTimeout }),
+	{
+		Key: "web-ui.theme", Category: "web_ui", Type: TypeEnum, Options: []string{"dark", "light"},
+		Description: "Default theme of the web UI and quote cards",
+		get:         func(c *Config) string { return c.WebUI.Theme },
+		set:         func(c *Config, v string) error { c.WebUI.Theme = v; return nil },
+	},
+	{
+		Key: "web-robots.allow", Category: "robots", Type: TypeList,
+		Description: "Paths allowed in robots.txt",
+		get:         func(c *Config) string { return strings.Join(c.WebRobots.Allow, ", ") },
+		set:         func(c *Config, v string) error { c.WebRobots.Allow = splitList(v); return nil },
+	},
+	{
+		Key: "web-robots.deny", Category: "robots", Type: TypeList,
+		Description: "Paths disallowed in robots.txt",
+		get:         func(c *Config) string { return strings.Join(c.WebRobots.Deny, ", ") },
+		set:         func(c *Config, v string) error { c.WebRobots.Deny = splitList(v); return nil },
+	},
+	{
+		Key: "web-security.admin", Category: "security", Type: TypeString,
+		Description: "Contact address published in security.txt",
+		get:         func(c *Config) string { return c.WebSecurity.Admin },
+		set:         func(c *Config, v string) error { c.WebSecurity.Admin = v; return nil },
+	},
+	{
+		Key: "web-security.cors", Category: "security", Type: TypeString,
+		Description: "Allowed CORS origins: * for any, or a comma-separated list",
+		get:         func(c *Config) string { return c.WebSecurity.CORS },
+		set:         func(c *Config, v string) error { c.WebSecurity.CORS = v; return nil },
+	},
+}
+
+// grpcSetting describes a switch in server.grpc. The gRPC server is set up
+// at startup, so changes take effect after a restart.
+func grpcSetting(key, description string, field func(*GRPCConfig) *bool) Setting {
+	return Setting{
+		Key: key, Category: "grpc", Type: TypeBool, RequiresRestart: true,
+		Description: description,
+		get:         func(c *Config) string { return strconv.FormatBool(*field(&c.Server.GRPC)) },
+		set: func(c *Config, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("%s must be true or false, got %q", key, v)
+			}
+			*field(&c.Server.GRPC) = b
+			return nil
+		},
+	}
+}
+
+// intSetting describes a whole-number limit in server.limits
+func intSetting(key, description string, restart bool, field func(*LimitsConfig) *int) Setting {
+	return Setting{
+		Key: key, Category: "limits", Type: TypeInt, RequiresRestart: restart,
+		Description: description,
+		get:         func(c *Config) string { return strconv.Itoa(*field(&c.Server.Limits)) },
+		set: func(c *Config, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s must be a whole number, got %q", key, v)
+			}
+			*field(&c.Server.Limits) = n
+			return nil
+		},
+	}
+}
+
+// durationSetting describes a timeout in server.limits
+func durationSetting(key, description string, restart bool, field func(*LimitsConfig) *time.Duration) Setting {
+	return Setting{
+		Key: key, Category: "limits", Type: TypeDuration, RequiresRestart: restart,
+		Description: description,
+		get:         func(c *Config) string { return field(&c.Server.Limits).String() },
+		set: func(c *Config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("%s must be a duration such as 30s or 2m, got %q", key, v)
+			}
+			*field(&c.Server.Limits) = d
+			return nil
+		},
+	}
+}
+
+// Settings returns the editable settings in display order
+func Settings() []Setting {
+	return slices.Clone(settings)
+}
+
+// LookupSetting finds a setting by key
+func LookupSetting(key string) (Setting, bool) {
+	for _, s := range settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Get returns the value of a setting
+func (c *Config) Get(key string) (string, bool) {
+	s, ok := LookupSetting(key)
+	if !ok {
+		return "", false
+	}
+	return s.get(c), true
+}
+
+// Set changes a setting. Only the value's form is checked here; call
+// Validate once all changes are made.
+func (c *Config) Set(key, value string) error {
+	s, ok := LookupSetting(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	value = strings.TrimSpace(value)
+	if s.Type == TypeEnum && !slices.Contains(s.Options, value) {
+		return fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(s.Options, ", "), value)
+	}
+	return s.set(c, value)
+}
+
+// Values returns every setting's value by key
+func (c *Config) Values() map[string]string {
+	values := make(map[string]string, len(settings))
+	for _, s := range settings {
+		values[s.Key] = s.get(c)
+	}
+	return values
+}
+
+// ChangedSettings returns the settings whose values differ between two
+// configurations, in display order
+func ChangedSettings(old, new *Config) []Setting {
+	var changed []Setting
+	for _, s := range settings {
+		if s.get(old) != s.get(new) {
+			changed = append(changed, s)
+		}
+	}
+	return changed
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	_ "embed"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -11,10 +14,12 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/apimgr/anime/src/anime"
+	"github.com/apimgr/anime/src/auth"
 	"github.com/apimgr/anime/src/config"
 	"github.com/apimgr/anime/src/paths"
 	"github.com/apimgr/anime/src/server"
@@ -31,6 +36,10 @@ var (
 
 const projectName = "anime"
 
+// shutdownTimeout is how long requests and gRPC calls in progress get to
+// finish on SIGTERM or interrupt
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 	// Get default directories
 	configDir, dataDir, logsDir := paths.GetDefaultDirs(projectName)
@@ -49,9 +58,23 @@ func main() {
 	serviceCmd := flag.String("service", "", "Service commands: start, stop, restart, reload, status, --install, --uninstall, --disable, --help")
 
 	// Maintenance commands
-	maintenanceCmd := flag.String("maintenance", "", "Maintenance commands: backup, restore, update")
+	maintenanceCmd := flag.String("maintenance", "", "Maintenance commands: backup, restore, update, validate")
+	outputFormat := flag.String("format", "text", "Output format for maintenance and token commands: text, json")
+
+	// Export commands
+	exportCmd := flag.String("export", "", "Export quotes: fortune")
+	var animeFilter, characterFilter stringList
+	flag.Var(&animeFilter, "anime", "Only export quotes from this anime (repeatable)")
+	flag.Var(&characterFilter, "character", "Only export quotes by this character (repeatable)")
+
+	// Token commands
+	tokenCmd := flag.String("token", "", "Admin token commands: create, list, revoke")
+	tokenName := flag.String("name", "", "Name of a new token")
+	var tokenScopes stringList
+	flag.Var(&tokenScopes, "scope", "Scope of a new token (repeatable, default: all scopes)")
 
 	flag.Parse()
+	args := positionalArgs()
 
 	// Handle help flag
 	if *help {
@@ -121,7 +144,20 @@ func main() {
 
 	// Handle maintenance commands
 	if *maintenanceCmd != "" {
-		handleMaintenanceCommand(*maintenanceCmd, configDir, dataDir, logsDir)
+		handleMaintenanceCommand(*maintenanceCmd, args, *outputFormat, configDir, dataDir, logsDir)
+		return
+	}
+
+	// Handle export commands
+	if *exportCmd != "" {
+		filter := anime.Filter{Anime: animeFilter, Character: characterFilter}
+		handleExportCommand(*exportCmd, args, filter, dataDir)
+		return
+	}
+
+	// Handle token commands
+	if *tokenCmd != "" {
+		handleTokenCommand(*tokenCmd, args, *tokenName, tokenScopes, *outputFormat, configDir)
 		return
 	}
 
@@ -154,6 +190,16 @@ func main() {
 		log.Fatalf("Failed to initialize anime service: %v", err)
 	}
 
+	// Merge quote packs from the data directory and watch for changes
+	quotesDir := filepath.Join(dataDir, "quotes.d")
+	if err := paths.EnsureDir(quotesDir); err != nil {
+		log.Printf("Failed to create quote pack directory: %v", err)
+	}
+	if err := animeService.LoadDir(quotesDir); err != nil {
+		log.Printf("Failed to load quote packs, using embedded quotes only: %v", err)
+	}
+	go animeService.Watch(context.Background(), 5*time.Second)
+
 	log.Printf("Loaded %d anime quotes", animeService.GetTotalQuotes())
 
 	// Setup signal handling for graceful shutdown
@@ -161,7 +207,7 @@ func main() {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
 	// Create and start HTTP server
-	srv, err := server.NewServer(animeService, cfg, serverPort, serverAddress)
+	srv, err := server.NewServer(animeService, cfg, configPath, serverPort, serverAddress)
 	if err != nil {
 		log.Fatalf("Failed to create server: %v", err)
 	}
@@ -181,13 +227,21 @@ func main() {
 			switch sig {
 			case syscall.SIGHUP:
 				log.Println("Received SIGHUP, reloading configuration...")
-				if _, err := config.Load(configPath); err != nil {
-					log.Printf("Failed to reload configuration: %v", err)
+				if err := srv.ReloadConfig(); err != nil {
+					log.Printf("Failed to reload configuration, keeping the current settings: %v", err)
+				}
+				if err := animeService.Reload(); err != nil {
+					log.Printf("Failed to reload quote packs: %v", err)
 				} else {
-					log.Println("Configuration reloaded successfully")
+					log.Printf("Quote packs reloaded (%d quotes)", animeService.GetTotalQuotes())
 				}
 			default:
 				log.Printf("Received signal %v, shutting down...", sig)
+				ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+				if err := srv.Shutdown(ctx); err != nil {
+					log.Printf("Shutdown did not finish cleanly: %v", err)
+				}
+				cancel()
 				log.Println("Shutdown complete")
 				os.Exit(0)
 			}
@@ -214,7 +268,7 @@ Service Commands:
   --service start      Start the service
   --service stop       Stop the service
   --service restart    Restart the service
-  --service reload     Reload configuration
+  --service reload     Reload server.yml and quote packs (SIGHUP)
   --service status     Show service status
   --service --install  Install as system service
   --service --uninstall Remove system service
@@ -224,6 +278,21 @@ Maintenance Commands:
   --maintenance backup [file]   Backup configuration and data
   --maintenance restore [file]  Restore from backup
   --maintenance update          Check for and install updates
+  --maintenance validate [file] Lint a quote file (default: built-in quotes and quote packs)
+                                Use --format json for machine-readable output
+
+Export Commands:
+  --export fortune <dir>        Write a fortune(6) file and strfile index to <dir>/anime
+                                and <dir>/anime.dat (try: fortune <dir>/anime)
+  --anime NAME                  Only export quotes from this anime (repeatable)
+  --character NAME              Only export quotes by this character (repeatable)
+
+Token Commands:
+  --token create --name NAME    Create an admin token (printed once; only its hash is stored)
+  --scope SCOPE                 Scope of the new token (repeatable): admin:read, admin:write
+                                (default: both)
+  --token list                  List tokens (--format json for machine-readable output)
+  --token revoke <id>           Revoke a token
 
 Environment Variables:
   PORT         Server port
@@ -232,6 +301,11 @@ Environment Variables:
   DATA_DIR     Data directory
   LOGS_DIR     Logs directory
 
+Quote Packs:
+  Extra quotes in JSON, YAML or CSV files placed in <data>/quotes.d/ are
+  merged with the built-in quotes. Changes are picked up automatically
+  and on SIGHUP.
+
 Configuration:
   Root:    /etc/apimgr/anime/server.yml
   User:    ~/.config/apimgr/anime/server.yml
@@ -280,9 +354,7 @@ func handleServiceCommand(cmd, configDir string) {
 	}
 }
 
-func handleMaintenanceCommand(cmd, configDir, dataDir, logsDir string) {
-	args := flag.Args()
-
+func handleMaintenanceCommand(cmd string, args []string, format, configDir, dataDir, logsDir string) {
 	switch cmd {
 	case "backup":
 		backupFile := ""
@@ -305,9 +377,15 @@ func handleMaintenanceCommand(cmd, configDir, dataDir, logsDir string) {
 		maintenanceRestore(args[0], configDir, dataDir)
 	case "update":
 		maintenanceUpdate()
+	case "validate":
+		file := ""
+		if len(args) > 0 {
+			file = args[0]
+		}
+		maintenanceValidate(file, dataDir, format)
 	default:
 		fmt.Printf("Unknown maintenance command: %s\n", cmd)
-		fmt.Println("Available commands: backup, restore, update")
+		fmt.Println("Available commands: backup, restore, update, validate")
 		os.Exit(1)
 	}
 }
@@ -417,6 +495,7 @@ After=network.target
 [Service]
 Type=simple
 ExecStart=/usr/local/bin/anime --config %s
+ExecReload=/bin/kill -HUP $MAINPID
 Restart=always
 RestartSec=5
 User=anime
@@ -499,6 +578,202 @@ func maintenanceUpdate() {
 	fmt.Println("Visit https://github.com/apimgr/anime/releases for the latest version")
 }
 
+// maintenanceValidate lints a quote file, or the embedded dataset and every
+// quote pack when no file is given, and exits non-zero if errors were found
+func maintenanceValidate(file, dataDir, format string) {
+	if format != "text" && format != "json" {
+		log.Fatalf("Unknown output format: %s (use text or json)", format)
+	}
+
+	var reports []*anime.Report
+	if file != "" {
+		report, err := anime.ValidateFile(file)
+		if err != nil {
+			log.Fatalf("Validation failed: %v", err)
+		}
+		reports = append(reports, report)
+	} else {
+		report := anime.ValidateData(animeData, anime.FormatJSON)
+		report.File = "(embedded dataset)"
+		reports = append(reports, report)
+
+		packs, err := anime.PackFiles(filepath.Join(dataDir, "quotes.d"))
+		if err != nil {
+			log.Fatalf("Failed to list quote packs: %v", err)
+		}
+		for _, pack := range packs {
+			report, err := anime.ValidateFile(pack)
+			if err != nil {
+				log.Fatalf("Validation failed: %v", err)
+			}
+			reports = append(reports, report)
+		}
+	}
+
+	errors := 0
+	for _, report := range reports {
+		errors += report.Errors
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			log.Fatalf("Failed to encode report: %v", err)
+		}
+	} else {
+		for _, report := range reports {
+			fmt.Print(report.String())
+		}
+	}
+
+	if errors > 0 {
+		os.Exit(1)
+	}
+}
+
+// handleExportCommand writes the quotes, including quote packs, in an
+// external format
+func handleExportCommand(cmd string, args []string, filter anime.Filter, dataDir string) {
+	switch cmd {
+	case "fortune":
+		if len(args) == 0 {
+			fmt.Println("Usage: anime --export fortune <dir>")
+			os.Exit(1)
+		}
+		exportFortune(args[0], filter, dataDir)
+	default:
+		fmt.Printf("Unknown export command: %s\n", cmd)
+		fmt.Println("Available commands: fortune")
+		os.Exit(1)
+	}
+}
+
+// exportFortune writes the quotes matching the filter as a fortune file
+// and its strfile .dat index
+func exportFortune(dir string, filter anime.Filter, dataDir string) {
+	animeService, err := anime.NewService(animeData)
+	if err != nil {
+		log.Fatalf("Failed to initialize anime service: %v", err)
+	}
+	if err := animeService.LoadDir(filepath.Join(dataDir, "quotes.d")); err != nil {
+		log.Printf("Failed to load quote packs, using embedded quotes only: %v", err)
+	}
+
+	quotes := animeService.FilterQuotes(filter)
+	if len(quotes) == 0 {
+		log.Fatalf("No quotes match the given filters")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Fatalf("Failed to create export directory: %v", err)
+	}
+	file := filepath.Join(dir, projectName)
+	if err := writeExportFile(file, quotes, anime.WriteFortunes); err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+	if err := writeExportFile(file+".dat", quotes, anime.WriteStrfile); err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+
+	fmt.Printf("Exported %d quotes to %s (index: %s.dat)\n", len(quotes), file, file)
+}
+
+// writeExportFile creates path and writes the quotes to it
+func writeExportFile(path string, quotes []anime.Quote, write func(io.Writer, []anime.Quote) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, quotes); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func handleTokenCommand(cmd string, args []string, name string, scopes []string, format, configDir string) {
+	store := auth.NewStore(auth.Path(configDir))
+	switch cmd {
+	case "create":
+		if name == "" {
+			fmt.Println("Usage: anime --token create --name NAME [--scope SCOPE ...]")
+			os.Exit(1)
+		}
+		if len(scopes) == 0 {
+			scopes = auth.Scopes
+		}
+		secret, token, err := store.Create(name, scopes)
+		if err != nil {
+			log.Fatalf("Failed to create token: %v", err)
+		}
+		fmt.Printf("Created token %s (%s) with scopes %s\n\n", token.ID, token.Name, strings.Join(token.Scopes, ", "))
+		fmt.Printf("  %s\n\n", secret)
+		fmt.Println("Store it now, it cannot be shown again. Send it as \"Authorization: Bearer <token>\"")
+		fmt.Println("or sign in at /admin/login.")
+	case "list":
+		tokens, err := store.List()
+		if err != nil {
+			log.Fatalf("Failed to read tokens: %v", err)
+		}
+		if format == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(tokens); err != nil {
+				log.Fatalf("Failed to encode tokens: %v", err)
+			}
+			return
+		}
+		if len(tokens) == 0 {
+			fmt.Println("No tokens. Create one with: anime --token create --name NAME")
+			return
+		}
+		fmt.Printf("%-10s %-20s %-25s %s\n", "ID", "NAME", "SCOPES", "CREATED")
+		for _, t := range tokens {
+			fmt.Printf("%-10s %-20s %-25s %s\n", t.ID, t.Name, strings.Join(t.Scopes, ","), t.CreatedAt.Format(time.RFC3339))
+		}
+	case "revoke":
+		if len(args) == 0 {
+			fmt.Println("Usage: anime --token revoke <id>")
+			os.Exit(1)
+		}
+		if err := store.Revoke(args[0]); err != nil {
+			log.Fatalf("Failed to revoke token: %v", err)
+		}
+		fmt.Printf("Revoked token %s\n", args[0])
+	default:
+		fmt.Printf("Unknown token command: %s\n", cmd)
+		fmt.Println("Available commands: create, list, revoke")
+		os.Exit(1)
+	}
+}
+
+// stringList is a flag that can be repeated to collect several values
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// positionalArgs returns the non-flag arguments. Flags given after them
+// are parsed too, so "--maintenance validate pack.yaml --format json"
+// works the same as putting --format first.
+func positionalArgs() []string {
+	var args []string
+	for flag.NArg() > 0 {
+		args = append(args, flag.Arg(0))
+		if err := flag.CommandLine.Parse(flag.Args()[1:]); err != nil {
+			log.Fatalf("Failed to parse arguments: %v", err)
+		}
+	}
+	return args
+}
+
 func runCommand(name string, args ...string) {
 	cmd := exec.Command(name, args...)
 	cmd.Stdout = os.Stdout
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apimgr/anime/src/config"
+)
+
+// settingsCategories names the settings categories in display order
+var settingsCategories = []struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}{
+	{"server", "Server"},
+	{"grpc", "gRPC"},
+	{"limits", "Limits"},
+	{"web_ui", "Web UI"},
+	{"robots", "Robots"},
+	{"security", "Security"},
+}
+
+// settingView is a setting with its current and default values
+type settingView struct {
+	config.Setting
+	Value   string `json:"value"`
+	Default string `json:"default"`
+}
+
+// handleAdmin renders the admin dashboard
+func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":           "Admin Dashboard",
+		"Page":            "admin",
+		"Theme":           s.config().WebUI.Theme,
+		"IsAdmin":         true,
+		"TokenName":       adminToken(r).Name,
+		"TotalQuotes":     s.animeService.GetTotalQuotes(),
+		"TotalAnime":      s.animeService.GetTotalAnime(),
+		"TotalCharacters": s.animeService.GetTotalCharacters(),
+		"Uptime":          time.Since(s.startTime).Round(time.Second).String(),
+		"StartTime":       s.startTime.UTC().Format(time.RFC3339),
+		"Version":         apiVersion,
+		"ServerAddress":   s.address,
+		"ServerPort":      s.port,
+		"BaseURL":         s.baseURL(r),
+		"ConfigFile":      s.configPath,
+		"GoVersion":       runtime.Version(),
+	}
+	if err := renderPage(w, http.StatusOK, "admin", data); err != nil {
+		log.Printf("Error rendering template: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleAdminSettings renders the settings page
+func (s *Server) handleAdminSettings(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":   "Server Settings",
+		"Page":    "admin",
+		"Theme":   s.config().WebUI.Theme,
+		"IsAdmin": true,
+	}
+	if err := renderPage(w, http.StatusOK, "admin_settings", data); err != nil {
+		log.Printf("Error rendering template: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleGetSettings lists the settings with their current values
+func (s *Server) handleGetSettings(w http.ResponseWriter, r *http.Request) {
+	cfg := s.config()
+	defaults := config.Default()
+
+	views := make(map[string][]settingView)
+	for _, setting := range config.Settings() {
+		value, _ := cfg.Get(setting.Key)
+		def, _ := defaults.Get(setting.Key)
+		views[setting.Category] = append(views[setting.Category], settingView{Setting: setting, Value: value, Default: def})
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"configFile": s.configPath,
+		"categories": settingsCategories,
+		"settings":   views,
+	})
+}
+
+// handleUpdateSettings changes settings from a JSON object of keys and values
+func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	s.applySettings(w, r, "updatedCount")
+}
+
+// handleImportSettings applies settings exported by handleExportSettings
+func (s *Server) handleImportSettings(w http.ResponseWriter, r *http.Request) {
+	s.applySettings(w, r, "importedCount")
+}
+
+// applySettings applies the settings in the request body and saves them.
+// Either every change is applied or none is.
+func (s *Server) applySettings(w http.ResponseWriter, r *http.Request, countField string) {
+	var body map[string]interface{}
+	if err := parseJSON(r, &body); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid JSON body: %v", err)})
+		return
+	}
+	if len(body) == 0 {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "no settings given"})
+		return
+	}
+
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+
+	// Apply in a fixed order so the first error reported is stable
+	keys := make([]string, 0, len(body))
+	for key := range body {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	old := s.config()
+	cfg := old.Clone()
+	for _, key := range keys {
+		value, err := settingValue(body[key])
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("%s: %v", key, err)})
+			return
+		}
+		if err := cfg.Set(key, value); err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	restart, err := s.saveConfig(old, cfg)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		countField:        len(keys),
+		"requiresRestart": restart,
+	})
+}
+
+// handleResetSettings restores the default configuration
+func (s *Server) handleResetSettings(w http.ResponseWriter, r *http.Request) {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+
+	restart, err := s.saveConfig(s.config(), config.Default())
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{"requiresRestart": restart})
+}
+
+// handleExportSettings downloads the current settings as JSON, in the form
+// the import endpoint accepts
+func (s *Server) handleExportSettings(w http.ResponseWriter, r *http.Request) {
+	data, err := json.MarshalIndent(s.config().Values(), "", "  ")
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="anime-settings.json"`)
+	w.Write(append(data, '\n'))
+}
+
+// saveConfig writes cfg to the config file and makes it the live
+// configuration. It reports whether a changed setting only takes effect
+// after a restart.
+func (s *Server) saveConfig(old, cfg *config.Config) (bool, error) {
+	changed := config.ChangedSettings(old, cfg)
+	if len(changed) == 0 {
+		return false, nil
+	}
+	if err := cfg.Save(s.configPath); err != nil {
+		return false, fmt.Errorf("failed to save %s: %v", s.configPath, err)
+	}
+	s.setConfig(cfg)
+
+	restart := false
+	keys := make([]string, len(changed))
+	for i, setting := range changed {
+		keys[i] = setting.Key
+		restart = restart || setting.RequiresRestart
+	}
+	log.Printf("Settings updated from the admin page: %s", strings.Join(keys, ", "))
+	return restart, nil
+}
+
+// settingValue converts a JSON value to the string form settings use.
+// Lists may be given as arrays.
+func settingValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		// Not fmt.Sprint, which writes large numbers as 1e+06
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case nil:
+		return "", nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("list entries must be strings")
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ", "), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+}
@@ -0,0 +1,297 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/apimgr/anime/src/auth"
+	"github.com/apimgr/anime/src/config"
+)
+
+// originalConfig is the server.yml newAdminServer starts from
+const originalConfig = `# edited by hand
+server:
+  limits:
+    api_rps: 40
+`
+
+// newAdminServer returns a test server loaded from originalConfig and an
+// admin token with both scopes
+func newAdminServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	s := newTestServer(t)
+	if err := os.WriteFile(s.configPath, []byte(originalConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(s.configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.setConfig(cfg)
+
+	secret, _, err := s.tokens.Create("test", []string{auth.ScopeAdminRead, auth.ScopeAdminWrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, secret
+}
+
+// adminRequest sends an authenticated request to the server
+func adminRequest(s *Server, secret, method, path, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+secret)
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+// settingsResponse is the body of a successful settings change
+type settingsResponse struct {
+	UpdatedCount    int  `json:"updatedCount"`
+	ImportedCount   int  `json:"importedCount"`
+	RequiresRestart bool `json:"requiresRestart"`
+}
+
+func TestUpdateSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		restart bool
+		apiRPS  int
+		errText string
+	}{
+		{"live setting", `{"server.limits.api_rps": 20}`, http.StatusOK, false, 20, ""},
+		{"string value", `{"server.limits.api_rps": "25"}`, http.StatusOK, false, 25, ""},
+		{"large number", `{"server.limits.api_rps": 1000000}`, http.StatusOK, false, 1000000, ""},
+		{"large limit", `{"server.limits.max_concurrent": 1000000}`, http.StatusOK, false, 40, ""},
+		{"restart setting", `{"server.limits.max_header_kb": 64}`, http.StatusOK, true, 40, ""},
+		{"mixed", `{"server.limits.api_rps": 20, "server.port": "9000"}`, http.StatusOK, true, 20, ""},
+		{"not a number", `{"server.limits.api_rps": "many"}`, http.StatusBadRequest, false, 40, "whole number"},
+		{"out of range", `{"server.limits.api_rps": 0}`, http.StatusBadRequest, false, 40, "at least 1"},
+		{"streams above concurrency", `{"server.limits.max_streams": 5000}`, http.StatusBadRequest, false, 40, "below server.limits.max_concurrent"},
+		{"one bad value", `{"server.limits.api_rps": 20, "web-ui.theme": "purple"}`, http.StatusBadRequest, false, 40, "must be one of"},
+		{"unknown key", `{"server.colour": "red"}`, http.StatusBadRequest, false, 40, "unknown setting"},
+		{"empty", `{}`, http.StatusBadRequest, false, 40, "no settings"},
+		{"not JSON", `api_rps=20`, http.StatusBadRequest, false, 40, "invalid JSON"},
+	}
+	for _, tt := range tests {
+		s, secret := newAdminServer(t)
+		w := adminRequest(s, secret, "PUT", "/api/v1/admin/settings", tt.body)
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d %s, want %d", tt.name, w.Code, w.Body.String(), tt.status)
+			continue
+		}
+
+		data, err := os.ReadFile(s.configPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved, err := config.Load(s.configPath)
+		if err != nil {
+			t.Fatalf("%s: saved file does not load: %v", tt.name, err)
+		}
+		if got := s.config().Server.Limits.APIRPS; got != tt.apiRPS {
+			t.Errorf("%s: live api_rps = %d, want %d", tt.name, got, tt.apiRPS)
+		}
+
+		if tt.status != http.StatusOK {
+			if !strings.Contains(w.Body.String(), tt.errText) {
+				t.Errorf("%s: error %s, want one mentioning %q", tt.name, w.Body.String(), tt.errText)
+			}
+			if string(data) != originalConfig {
+				t.Errorf("%s: rejected change rewrote the file:\n%s", tt.name, data)
+			}
+			continue
+		}
+
+		var resp settingsResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.RequiresRestart != tt.restart {
+			t.Errorf("%s: requiresRestart = %v, want %v", tt.name, resp.RequiresRestart, tt.restart)
+		}
+		if want := strings.Count(tt.body, `": `); resp.UpdatedCount != want {
+			t.Errorf("%s: updatedCount = %d, want %d", tt.name, resp.UpdatedCount, want)
+		}
+		if saved.Server.Limits.APIRPS != tt.apiRPS {
+			t.Errorf("%s: saved api_rps = %d, want %d", tt.name, saved.Server.Limits.APIRPS, tt.apiRPS)
+		}
+	}
+}
+
+func TestUpdateSettingsNeedsWriteScope(t *testing.T) {
+	s, _ := newAdminServer(t)
+	secret, _, err := s.tokens.Create("reader", []string{auth.ScopeAdminRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := adminRequest(s, secret, "GET", "/api/v1/admin/settings", ""); w.Code != http.StatusOK {
+		t.Errorf("GET with admin:read: %d", w.Code)
+	}
+	if w := adminRequest(s, secret, "PUT", "/api/v1/admin/settings", `{"server.limits.api_rps": 20}`); w.Code != http.StatusForbidden {
+		t.Errorf("PUT with admin:read: %d, want 403", w.Code)
+	}
+	if w := adminRequest(s, "", "PUT", "/api/v1/admin/settings", `{"server.limits.api_rps": 20}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("PUT without a token: %d, want 401", w.Code)
+	}
+	if data, _ := os.ReadFile(s.configPath); string(data) != originalConfig {
+		t.Errorf("rejected requests rewrote the file:\n%s", data)
+	}
+}
+
+func TestExportImportSettings(t *testing.T) {
+	s, secret := newAdminServer(t)
+	export := adminRequest(s, secret, "GET", "/api/v1/admin/settings/export", "")
+	if export.Code != http.StatusOK {
+		t.Fatalf("export: %d", export.Code)
+	}
+	exported := export.Body.String()
+
+	// Change a live and a restart setting, then import the export back
+	adminRequest(s, secret, "PUT", "/api/v1/admin/settings", `{"server.limits.api_rps": 20, "server.limits.max_header_kb": 64}`)
+	w := adminRequest(s, secret, "POST", "/api/v1/admin/settings/import", exported)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import: %d %s", w.Code, w.Body.String())
+	}
+	var resp settingsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ImportedCount != len(config.Settings()) || !resp.RequiresRestart {
+		t.Errorf("import: %+v, want every setting imported and a restart", resp)
+	}
+	if again := adminRequest(s, secret, "GET", "/api/v1/admin/settings/export", ""); again.Body.String() != exported {
+		t.Errorf("export after import:\n%s\nwant\n%s", again.Body.String(), exported)
+	}
+
+	// An invalid import changes nothing
+	before, _ := os.ReadFile(s.configPath)
+	invalid := strings.Replace(exported, `"server.limits.max_streams": "100"`, `"server.limits.max_streams": "5000"`, 1)
+	if invalid == exported {
+		t.Fatal("export does not contain max_streams")
+	}
+	if w := adminRequest(s, secret, "POST", "/api/v1/admin/settings/import", invalid); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid import: %d, want 400", w.Code)
+	}
+	if after, _ := os.ReadFile(s.configPath); !bytes.Equal(after, before) {
+		t.Errorf("invalid import rewrote the file")
+	}
+	if got := s.config().Server.Limits.MaxStreams; got != 100 {
+		t.Errorf("invalid import applied max_streams %d", got)
+	}
+}
+
+func TestResetSettings(t *testing.T) {
+	s, secret := newAdminServer(t)
+	w := adminRequest(s, secret, "POST", "/api/v1/admin/settings/reset", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("reset: %d %s", w.Code, w.Body.String())
+	}
+	var resp settingsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.RequiresRestart {
+		t.Errorf("resetting api_rps reported a restart")
+	}
+	if got := s.config().Server.Limits.APIRPS; got != config.Default().Server.Limits.APIRPS {
+		t.Errorf("live api_rps = %d after reset", got)
+	}
+	saved, err := config.Load(s.configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := saved.Values(), config.Default().Values(); !maps.Equal(got, want) {
+		t.Errorf("saved settings after reset differ from the defaults")
+	}
+
+	// A restart setting away from its default is reported
+	adminRequest(s, secret, "PUT", "/api/v1/admin/settings", `{"server.port": "9000"}`)
+	w = adminRequest(s, secret, "POST", "/api/v1/admin/settings/reset", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || !resp.RequiresRestart {
+		t.Errorf("reset of server.port: %s, want requiresRestart", w.Body.String())
+	}
+}
+
+// login signs in through the login form from addr
+func login(s *Server, token, addr string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("POST", "/admin/login", strings.NewReader(url.Values{"token": {token}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.RemoteAddr = addr
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+// withCookie sends a request with the login cookie
+func withCookie(s *Server, method, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	r.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestLoginSession(t *testing.T) {
+	s, secret := newAdminServer(t)
+	w := login(s, secret, "192.0.2.1:1234")
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("login: %d", w.Code)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("login set %d cookies", len(cookies))
+	}
+	cookie := cookies[0]
+	if cookie.Value == "" || strings.Contains(cookie.Value, secret) {
+		t.Errorf("cookie holds %q, want a session instead of the token", cookie.Value)
+	}
+	if cookie.MaxAge != int(auth.SessionLifetime.Seconds()) || cookie.Expires.IsZero() || !cookie.HttpOnly {
+		t.Errorf("cookie %+v, want HttpOnly with Max-Age and Expires", cookie)
+	}
+
+	if w := withCookie(s, "GET", "/admin", cookie); w.Code != http.StatusOK {
+		t.Errorf("admin page with the session: %d", w.Code)
+	}
+	if w := withCookie(s, "POST", "/admin/logout", cookie); w.Code != http.StatusSeeOther {
+		t.Errorf("logout: %d", w.Code)
+	}
+	if w := withCookie(s, "GET", "/admin", cookie); w.Code != http.StatusSeeOther {
+		t.Errorf("admin page after logout: %d, want a redirect to the login page", w.Code)
+	}
+
+	// Revoking the token ends its sessions
+	cookie = login(s, secret, "192.0.2.1:1234").Result().Cookies()[0]
+	tokens, _ := s.tokens.List()
+	if err := s.tokens.Revoke(tokens[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if w := withCookie(s, "GET", "/admin", cookie); w.Code != http.StatusSeeOther {
+		t.Errorf("admin page after revoking the token: %d, want a redirect", w.Code)
+	}
+}
+
+func TestLoginRateLimit(t *testing.T) {
+	s, secret := newAdminServer(t)
+	for i := 0; i < loginAttempts; i++ {
+		if w := login(s, "anime_wrong", "192.0.2.1:1234"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: %d", i+1, w.Code)
+		}
+	}
+	if w := login(s, secret, "192.0.2.1:1234"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("attempt over the limit: %d, want 429", w.Code)
+	}
+	if w := login(s, secret, "192.0.2.2:1234"); w.Code != http.StatusSeeOther {
+		t.Errorf("another client: %d, want a login", w.Code)
+	}
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/apimgr/anime/src/auth"
+)
+
+// adminCookie holds the session of an admin signed in through the login
+// page
+const adminCookie = "anime_admin"
+
+// adminTokenKey is the context key of the authenticated token
+type adminTokenKey struct{}
+
+// adminToken returns the token that authenticated the request
+func adminToken(r *http.Request) auth.Token {
+	token, _ := r.Context().Value(adminTokenKey{}).(auth.Token)
+	return token
+}
+
+// requestToken returns the secret sent with the request: a bearer token,
+// an X-API-Key header or the login session in the cookie
+func requestToken(r *http.Request) (secret string, fromCookie bool) {
+	if h := r.Header.Get("Authorization"); len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:]), false
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key, false
+	}
+	if c, err := r.Cookie(adminCookie); err == nil {
+		return c.Value, true
+	}
+	return "", false
+}
+
+// authenticate returns the token a secret from requestToken stands for.
+// A login session ends with the token it was made with.
+func (s *Server) authenticate(secret string, fromCookie bool) (auth.Token, bool, error) {
+	if !fromCookie {
+		return s.tokens.Authenticate(secret)
+	}
+	id, ok := s.sessions.Lookup(secret)
+	if !ok {
+		return auth.Token{}, false, nil
+	}
+	return s.tokens.Get(id)
+}
+
+// requiredScope returns the scope a request needs: reading for GET and
+// HEAD, writing for anything else
+func requiredScope(r *http.Request) string {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return auth.ScopeAdminRead
+	}
+	return auth.ScopeAdminWrite
+}
+
+// requireAdmin only lets requests with a valid token carrying the required
+// scope through. Pages send anonymous visitors to the login page; API
+// requests get 401.
+func (s *Server) requireAdmin(page bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secret, fromCookie := requestToken(r)
+			token, ok, err := s.authenticate(secret, fromCookie)
+			if err != nil {
+				log.Printf("Error reading tokens: %v", err)
+				respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to read tokens"})
+				return
+			}
+			if !ok {
+				if secret != "" {
+					log.Printf("Rejected invalid admin token or session for %s %s", r.Method, r.URL.Path)
+				}
+				if page {
+					http.Redirect(w, r, "/admin/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+					return
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer realm="anime admin"`)
+				respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "a valid admin token is required (create one with anime --token create)"})
+				return
+			}
+
+			scope := requiredScope(r)
+			if !token.HasScope(scope) {
+				respondJSON(w, http.StatusForbidden, map[string]string{"error": "token lacks the " + scope + " scope"})
+				return
+			}
+
+			// Browsers send the cookie with requests from any page, so
+			// changes made with it must come from this site
+			if fromCookie && scope == auth.ScopeAdminWrite {
+				if u, err := url.Parse(r.Header.Get("Origin")); err != nil || u.Host != r.Host {
+					respondJSON(w, http.StatusForbidden, map[string]string{"error": "cross-origin admin requests are not allowed"})
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminTokenKey{}, token)))
+		})
+	}
+}
+
+// handleLoginPage renders the admin login form
+func (s *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	s.renderLogin(w, http.StatusOK, r.URL.Query().Get("next"), "")
+}
+
+// handleLogin checks the submitted token and starts a login session, whose
+// secret goes in the cookie in place of the token
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		s.renderLogin(w, http.StatusBadRequest, "", "Invalid form")
+		return
+	}
+	next := r.PostForm.Get("next")
+	secret := strings.TrimSpace(r.PostForm.Get("token"))
+
+	token, ok, err := s.tokens.Authenticate(secret)
+	if err != nil {
+		log.Printf("Error reading tokens: %v", err)
+		s.renderLogin(w, http.StatusInternalServerError, next, "Failed to read tokens")
+		return
+	}
+	if !ok || !token.HasScope(auth.ScopeAdminRead) {
+		log.Printf("Failed admin login from %s", clientIP(r))
+		s.renderLogin(w, http.StatusUnauthorized, next, "Invalid token")
+		return
+	}
+
+	session, expires, err := s.sessions.Create(token.ID)
+	if err != nil {
+		log.Printf("Error creating session: %v", err)
+		s.renderLogin(w, http.StatusInternalServerError, next, "Failed to sign in")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     adminCookie,
+		Value:    session,
+		Path:     "/",
+		Expires:  expires,
+		MaxAge:   int(auth.SessionLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   requestScheme(r) == "https",
+		SameSite: http.SameSiteStrictMode,
+	})
+	log.Printf("Admin login with token %s (%s)", token.ID, token.Name)
+
+	// Only redirect within the admin pages
+	if !strings.HasPrefix(next, "/admin") || strings.HasPrefix(next, "/admin/login") {
+		next = "/admin"
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// handleLogout ends the login session and clears the cookie
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if c, err := r.Cookie(adminCookie); err == nil {
+		s.sessions.Revoke(c.Value)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     adminCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
+}
+
+// renderLogin renders the login page with an optional error
+func (s *Server) renderLogin(w http.ResponseWriter, status int, next, message string) {
+	data := map[string]interface{}{
+		"Title": "Admin Login",
+		"Page":  "admin",
+		"Theme": s.config().WebUI.Theme,
+		"Next":  next,
+		"Error": message,
+	}
+	if err := renderPage(w, status, "admin_login", data); err != nil {
+		log.Printf("Error rendering template: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,300 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/apimgr/anime/src/anime"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Quote card dimensions, sized for social media link previews
+const (
+	cardWidth   = 1200
+	cardHeight  = 630
+	cardPadding = 80
+	cardBar     = 12
+)
+
+// Quote card font sizes. The quote shrinks until it fits; the attribution
+// always uses the same sizes.
+var cardQuoteSizes = []float64{52, 46, 40, 36, 32, 28, 24}
+
+const (
+	cardCharacterSize = 32
+	cardAnimeSize     = 26
+	cardLineSpacing   = 1.35
+	cardGap           = 28
+)
+
+// cardTheme holds the colors of a quote card
+type cardTheme struct {
+	background color.RGBA
+	text       color.RGBA
+	muted      color.RGBA
+	accent     color.RGBA
+}
+
+// cardThemes mirrors the web-ui.theme colors in static/css/main.css
+var cardThemes = map[string]cardTheme{
+	"dark": {
+		background: color.RGBA{0x1a, 0x1a, 0x1a, 0xff},
+		text:       color.RGBA{0xff, 0xff, 0xff, 0xff},
+		muted:      color.RGBA{0xb0, 0xb0, 0xb0, 0xff},
+		accent:     color.RGBA{0x00, 0x66, 0xcc, 0xff},
+	},
+	"light": {
+		background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		text:       color.RGBA{0x1a, 0x1a, 0x1a, 0xff},
+		muted:      color.RGBA{0x40, 0x40, 0x40, 0xff},
+		accent:     color.RGBA{0x00, 0x66, 0xcc, 0xff},
+	},
+}
+
+// quoteCard is the response data for the image formats
+type quoteCard struct {
+	quote anime.Quote
+	theme cardTheme
+}
+
+// cardTheme picks the card colors from ?theme=, falling back to the
+// configured web UI theme and then to dark
+func (s *Server) cardTheme(r *http.Request) cardTheme {
+	if theme, ok := cardThemes[r.URL.Query().Get("theme")]; ok {
+		return theme
+	}
+	if theme, ok := cardThemes[s.config().WebUI.Theme]; ok {
+		return theme
+	}
+	return cardThemes["dark"]
+}
+
+// respondQuote renders a single quote, drawn as a card for the image formats
+func (s *Server) respondQuote(w http.ResponseWriter, r *http.Request, quote *anime.Quote) {
+	if requestFormat(r).image {
+		respond(w, r, http.StatusOK, quoteCard{quote: *quote, theme: s.cardTheme(r)})
+		return
+	}
+	respond(w, r, http.StatusOK, quote)
+}
+
+// cardFonts are parsed once on first use
+var cardFonts struct {
+	once    sync.Once
+	regular *opentype.Font
+	bold    *opentype.Font
+	err     error
+}
+
+// loadCardFonts parses the embedded Go fonts
+func loadCardFonts() error {
+	cardFonts.once.Do(func() {
+		if cardFonts.regular, cardFonts.err = opentype.Parse(goregular.TTF); cardFonts.err != nil {
+			return
+		}
+		cardFonts.bold, cardFonts.err = opentype.Parse(gobold.TTF)
+	})
+	return cardFonts.err
+}
+
+// cardText is one line of text placed on the card
+type cardText struct {
+	text     string
+	x, y     int
+	size     float64
+	bold     bool
+	color    color.RGBA
+	fontFace font.Face
+}
+
+// layoutCard wraps the quote and places every line of text. The quote uses
+// the largest size that fits; if even the smallest is too long, the last
+// line is cut short with an ellipsis.
+func layoutCard(card quoteCard) ([]cardText, error) {
+	if err := loadCardFonts(); err != nil {
+		return nil, err
+	}
+
+	textWidth := cardWidth - 2*cardPadding
+	attribution := cardGap + cardCharacterSize*cardLineSpacing + cardAnimeSize*cardLineSpacing
+	available := float64(cardHeight-2*cardPadding) - attribution
+
+	quote := "“" + strings.TrimSpace(card.quote.Quote) + "”"
+	var face font.Face
+	var lines []string
+	var size float64
+	for _, size = range cardQuoteSizes {
+		var err error
+		if face, err = newCardFace(cardFonts.regular, size); err != nil {
+			return nil, err
+		}
+		lines = wrapText(face, quote, textWidth)
+		if float64(len(lines))*size*cardLineSpacing <= available {
+			break
+		}
+	}
+	if maxLines := int(available / (size * cardLineSpacing)); len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] = truncateText(face, lines[maxLines-1]+"…", textWidth)
+	}
+
+	characterFace, err := newCardFace(cardFonts.bold, cardCharacterSize)
+	if err != nil {
+		return nil, err
+	}
+	animeFace, err := newCardFace(cardFonts.regular, cardAnimeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Center the quote and attribution as one block
+	blockHeight := float64(len(lines))*size*cardLineSpacing + attribution
+	y := (float64(cardHeight) - blockHeight) / 2
+
+	var texts []cardText
+	for _, line := range lines {
+		y += size * cardLineSpacing
+		texts = append(texts, cardText{text: line, x: cardPadding, y: int(y), size: size, color: card.theme.text, fontFace: face})
+	}
+	y += cardGap + cardCharacterSize*cardLineSpacing
+	texts = append(texts, cardText{
+		text: truncateText(characterFace, "— "+card.quote.Character, textWidth), x: cardPadding, y: int(y),
+		size: cardCharacterSize, bold: true, color: card.theme.accent, fontFace: characterFace,
+	})
+	y += cardAnimeSize * cardLineSpacing
+	texts = append(texts, cardText{
+		text: truncateText(animeFace, card.quote.Anime, textWidth), x: cardPadding, y: int(y),
+		size: cardAnimeSize, color: card.theme.muted, fontFace: animeFace,
+	})
+	return texts, nil
+}
+
+// newCardFace creates a font face at the given pixel size
+func newCardFace(f *opentype.Font, size float64) (font.Face, error) {
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// wrapText splits text into lines no wider than width. Words longer than a
+// full line are broken between characters.
+func wrapText(face font.Face, text string, width int) []string {
+	limit := fixed.I(width)
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate) <= limit {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = word
+		for font.MeasureString(face, line) > limit {
+			runes := []rune(line)
+			n := len(runes) - 1
+			for n > 1 && font.MeasureString(face, string(runes[:n])) > limit {
+				n--
+			}
+			lines = append(lines, string(runes[:n]))
+			line = string(runes[n:])
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncateText shortens text with an ellipsis until it fits width
+func truncateText(face font.Face, text string, width int) string {
+	limit := fixed.I(width)
+	if font.MeasureString(face, text) <= limit {
+		return text
+	}
+	runes := []rune(strings.TrimSuffix(text, "…"))
+	for len(runes) > 0 && font.MeasureString(face, string(runes)+"…") > limit {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "…"
+}
+
+// encodeSVG draws a quote card as SVG
+func encodeSVG(w io.Writer, data interface{}) error {
+	card, ok := data.(quoteCard)
+	if !ok {
+		return errUnsupportedData
+	}
+	texts, err := layoutCard(card)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		cardWidth, cardHeight, cardWidth, cardHeight)
+	fmt.Fprintf(&sb, `  <rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(card.theme.background))
+	fmt.Fprintf(&sb, `  <rect width="%d" height="100%%" fill="%s"/>`+"\n", cardBar, hexColor(card.theme.accent))
+	sb.WriteString(`  <g font-family="Go, 'Helvetica Neue', Arial, sans-serif">` + "\n")
+	for _, t := range texts {
+		weight := ""
+		if t.bold {
+			weight = ` font-weight="bold"`
+		}
+		fmt.Fprintf(&sb, `    <text x="%d" y="%d" font-size="%g"%s fill="%s">`, t.x, t.y, t.size, weight, hexColor(t.color))
+		xml.EscapeText(&sb, []byte(t.text))
+		sb.WriteString("</text>\n")
+	}
+	sb.WriteString("  </g>\n</svg>\n")
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// encodePNG rasterizes a quote card as PNG
+func encodePNG(w io.Writer, data interface{}) error {
+	card, ok := data.(quoteCard)
+	if !ok {
+		return errUnsupportedData
+	}
+	texts, err := layoutCard(card)
+	if err != nil {
+		return err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(card.theme.background), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, cardBar, cardHeight), image.NewUniform(card.theme.accent), image.Point{}, draw.Src)
+
+	for _, t := range texts {
+		d := font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(t.color),
+			Face: t.fontFace,
+			Dot:  fixed.P(t.x, t.y),
+		}
+		d.DrawString(t.text)
+	}
+
+	return png.Encode(w, img)
+}
+
+// hexColor formats a color for SVG attributes
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// clientIPKey is the context key of the resolved client address
+type clientIPKey struct{}
+
+// requestSchemeKey is the context key of the scheme the client used
+type requestSchemeKey struct{}
+
+// clientIPMiddleware resolves the client address and scheme once per
+// request, so rate limiting, logging and links agree on them. See
+// resolveClient.
+func clientIPMiddleware(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip, scheme := resolveClient(r, trusted)
+			ctx := context.WithValue(r.Context(), clientIPKey{}, ip)
+			ctx = context.WithValue(ctx, requestSchemeKey{}, scheme)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// clientIP returns the client address resolved by clientIPMiddleware
+func clientIP(r *http.Request) netip.Addr {
+	if ip, ok := r.Context().Value(clientIPKey{}).(netip.Addr); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// requestScheme returns the scheme resolved by clientIPMiddleware: "https"
+// or "http"
+func requestScheme(r *http.Request) string {
+	if scheme, ok := r.Context().Value(requestSchemeKey{}).(string); ok {
+		return scheme
+	}
+	return connScheme(r)
+}
+
+// connScheme returns the scheme of the connection itself
+func connScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// remoteIP returns the address of the connection's peer
+func remoteIP(r *http.Request) netip.Addr {
+	if addr, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+		return addr.Addr().Unmap()
+	}
+	ip, _ := netip.ParseAddr(r.RemoteAddr)
+	return ip.Unmap()
+}
+
+// resolveClient returns the address of the client and the scheme it used.
+// Forwarding headers are only believed when the connection comes from a
+// trusted proxy. The chain of forwarded addresses is then walked from the
+// nearest hop back, and the first address that is not a trusted proxy is
+// the client. Anyone can put addresses at the start of the chain, so it is
+// never read blindly. The scheme is the one the proxy that reported the
+// client saw, so a client cannot claim https through a trusted proxy that
+// does not say so.
+func resolveClient(r *http.Request, trusted []netip.Prefix) (netip.Addr, string) {
+	ip, scheme := remoteIP(r), connScheme(r)
+	if !isTrustedProxy(ip, trusted) {
+		return ip, scheme
+	}
+
+	hops := forwardedHops(r.Header)
+	for i := len(hops) - 1; i >= 0; i-- {
+		if proto := strings.ToLower(strings.TrimSpace(hops[i].proto)); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		hop, ok := parseNode(hops[i].node)
+		if !ok {
+			// Hidden or unknown hop: the trusted proxy that added it is as
+			// close to the client as we can tell
+			return ip, scheme
+		}
+		ip = hop
+		if !isTrustedProxy(ip, trusted) {
+			return ip, scheme
+		}
+	}
+	return ip, scheme
+}
+
+// isTrustedProxy reports whether ip belongs to one of the trusted networks
+func isTrustedProxy(ip netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedHop is one forwarded client address and the scheme of the
+// request the proxy received from it, if the proxy said
+type forwardedHop struct {
+	node  string
+	proto string
+}
+
+// forwardedHops returns the forwarded client addresses, client first: the
+// for= and proto= parameters of RFC 7239 Forwarded headers, or else
+// X-Forwarded-For, or else X-Real-IP. Only one of them is read, so a proxy
+// that sets Forwarded decides the chain even if a client sent
+// X-Forwarded-For. X-Forwarded-Proto values are matched to the addresses
+// from the nearest hop back, since each proxy appends to both; without
+// addresses, the result is one hop with only a scheme.
+func forwardedHops(h http.Header) []forwardedHop {
+	var hops []forwardedHop
+	if values := h.Values("Forwarded"); len(values) > 0 {
+		for _, value := range values {
+			for _, element := range splitQuoted(value, ',') {
+				var hop forwardedHop
+				for _, pair := range splitQuoted(element, ';') {
+					key, value, _ := strings.Cut(pair, "=")
+					switch strings.ToLower(strings.TrimSpace(key)) {
+					case "for":
+						hop.node = value
+					case "proto":
+						hop.proto = strings.Trim(value, `"`)
+					}
+				}
+				hops = append(hops, hop)
+			}
+		}
+		return hops
+	}
+
+	var protos []string
+	for _, value := range h.Values("X-Forwarded-Proto") {
+		protos = append(protos, strings.Split(value, ",")...)
+	}
+	if values := h.Values("X-Forwarded-For"); len(values) > 0 {
+		for _, value := range values {
+			for _, node := range strings.Split(value, ",") {
+				hops = append(hops, forwardedHop{node: node})
+			}
+		}
+	} else if realIP := h.Get("X-Real-IP"); realIP != "" {
+		hops = []forwardedHop{{node: realIP}}
+	} else if len(protos) > 0 {
+		hops = []forwardedHop{{}}
+	}
+	for i, j := len(hops)-1, len(protos)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		hops[i].proto = protos[j]
+	}
+	return hops
+}
+
+// parseNode parses a forwarded address: a bare IP, or an RFC 7239 node such
+// as "192.0.2.43:47011" or "[2001:db8::1]:4711". Obfuscated identifiers and
+// "unknown" are rejected.
+func parseNode(node string) (netip.Addr, bool) {
+	node = strings.TrimSpace(node)
+	if unquoted, err := strconv.Unquote(node); err == nil {
+		node = unquoted
+	}
+	if strings.HasPrefix(node, "[") {
+		end := strings.Index(node, "]")
+		if end < 0 {
+			return netip.Addr{}, false
+		}
+		node = node[1:end]
+	} else if addr, err := netip.ParseAddrPort(node); err == nil {
+		return addr.Addr().Unmap(), true
+	}
+	ip, err := netip.ParseAddr(node)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+// splitQuoted splits s at sep, except inside double-quoted strings
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// rateLimitKey keys rate limits by client address. IPv6 clients are grouped
+// by /64, since a single host usually holds a whole /64.
+func rateLimitKey(r *http.Request) (string, error) {
+	ip := clientIP(r)
+	if !ip.IsValid() {
+		return r.RemoteAddr, nil
+	}
+	if ip.Is6() {
+		prefix, _ := ip.Prefix(64)
+		return prefix.String(), nil
+	}
+	return ip.String(), nil
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestResolveClient(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8:ffff::/48"),
+	}
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"untrusted peer is not believed", "203.0.113.7:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "203.0.113.7"},
+		{"trusted proxy without headers", "10.0.0.2:5000", nil, "10.0.0.2"},
+		{"x-forwarded-for", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"spoofed first entry", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.1"}, "198.51.100.1"},
+		{"proxy chain", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.1, 10.0.0.9"}, "198.51.100.1"},
+		{"x-real-ip", "10.0.0.2:5000", map[string]string{"X-Real-IP": "198.51.100.1"}, "198.51.100.1"},
+		{"forwarded", "10.0.0.2:5000", map[string]string{"Forwarded": `for=198.51.100.1;proto=https, for="10.0.0.9:8080"`}, "198.51.100.1"},
+		{"forwarded ipv6", "[2001:db8:ffff::1]:5000", map[string]string{"Forwarded": `For="[2001:db8:cafe::17]:4711"`}, "2001:db8:cafe::17"},
+		{"forwarded wins over x-forwarded-for", "10.0.0.2:5000", map[string]string{"Forwarded": "for=198.51.100.1", "X-Forwarded-For": "192.0.2.99"}, "198.51.100.1"},
+		{"obfuscated hop", "10.0.0.2:5000", map[string]string{"Forwarded": "for=198.51.100.1, for=_hidden"}, "10.0.0.2"},
+		{"all hops trusted", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "10.1.1.1, 10.0.0.9"}, "10.1.1.1"},
+		{"ipv4-mapped peer", "[::ffff:10.0.0.2]:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remote
+		for key, value := range tt.headers {
+			r.Header.Set(key, value)
+		}
+		if got, _ := resolveClient(r, trusted); got.String() != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveClientScheme(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "http"},
+		{"untrusted peer claims https", "203.0.113.7:5000", map[string]string{"X-Forwarded-Proto": "https"}, "http"},
+		{"untrusted peer claims https with forwarded", "203.0.113.7:5000", map[string]string{"Forwarded": "for=198.51.100.1;proto=https"}, "http"},
+		{"trusted proxy, proto only", "10.0.0.2:5000", map[string]string{"X-Forwarded-Proto": "https"}, "https"},
+		{"trusted proxy with x-forwarded-for", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Forwarded-Proto": "HTTPS"}, "https"},
+		{"trusted proxy with x-real-ip", "10.0.0.2:5000", map[string]string{"X-Real-IP": "198.51.100.1", "X-Forwarded-Proto": "https"}, "https"},
+		{"client-sent proto before the proxy's", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Forwarded-Proto": "https, http"}, "http"},
+		{"proto of the outer proxy in a chain", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.1, 10.0.0.9", "X-Forwarded-Proto": "https, http"}, "https"},
+		{"forwarded", "10.0.0.2:5000", map[string]string{"Forwarded": `for=198.51.100.1;proto=https`}, "https"},
+		{"forwarded chain", "10.0.0.2:5000", map[string]string{"Forwarded": `for=198.51.100.1;proto=https, for=10.0.0.9;proto=http`}, "https"},
+		{"spoofed forwarded entry", "10.0.0.2:5000", map[string]string{"Forwarded": `for=1.1.1.1;proto=https, for=198.51.100.1;proto=http`}, "http"},
+		{"forwarded wins over x-forwarded-proto", "10.0.0.2:5000", map[string]string{"Forwarded": "for=198.51.100.1", "X-Forwarded-Proto": "https"}, "http"},
+		{"unknown scheme", "10.0.0.2:5000", map[string]string{"X-Forwarded-Proto": "gopher"}, "http"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remote
+		for key, value := range tt.headers {
+			r.Header.Set(key, value)
+		}
+		if _, got := resolveClient(r, trusted); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestForwardedProtoNeedsTrustedProxy(t *testing.T) {
+	s, secret := newAdminServer(t)
+	cfg := *s.config()
+	cfg.Server.TrustedProxies = []string{"10.0.0.0/8"}
+	s.setConfig(&cfg)
+
+	for _, tt := range []struct {
+		remote string
+		https  bool
+	}{
+		{"203.0.113.7:5000", false},
+		{"10.0.0.2:5000", true},
+	} {
+		r := httptest.NewRequest("GET", "/feed.json", nil)
+		r.RemoteAddr = tt.remote
+		r.Header.Set("X-Forwarded-Proto", "https")
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if got := strings.Contains(w.Body.String(), `"home_page_url":"https://`); got != tt.https {
+			t.Errorf("%s: feed links use https = %v, want %v", tt.remote, got, tt.https)
+		}
+
+		r = httptest.NewRequest("POST", "/admin/login", strings.NewReader(url.Values{"token": {secret}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("X-Forwarded-Proto", "https")
+		r.RemoteAddr = tt.remote
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].Secure != tt.https {
+			t.Errorf("%s: login cookies %v, want one with Secure %v", tt.remote, cookies, tt.https)
+		}
+	}
+}
@@ -0,0 +1,516 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/apimgr/anime/src/anime"
+	"gopkg.in/yaml.v3"
+)
+
+// nodeKind says what a node holds
+type nodeKind int
+
+const (
+	scalarNode nodeKind = iota
+	objectNode
+	listNode
+)
+
+// node is a format-neutral view of a response. Objects keep their JSON
+// field names and order so every format agrees with the JSON output.
+type node struct {
+	kind   nodeKind
+	value  string
+	tag    string
+	keys   []string
+	fields []node
+	items  []node
+}
+
+// toNode converts a response value into a node tree, following the same
+// json struct tags and omitempty rules as encoding/json
+func toNode(v reflect.Value) node {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return node{kind: scalarNode, tag: "!!null"}
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		n := node{kind: objectNode}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			fv := v.Field(i)
+			if strings.Contains(opts, "omitempty") && isEmptyValue(fv) {
+				continue
+			}
+			n.keys = append(n.keys, name)
+			n.fields = append(n.fields, toNode(fv))
+		}
+		return n
+
+	case reflect.Map:
+		n := node{kind: objectNode}
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		for _, k := range v.MapKeys() {
+			key := fmt.Sprint(k.Interface())
+			keys = append(keys, key)
+			values[key] = v.MapIndex(k)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			n.keys = append(n.keys, key)
+			n.fields = append(n.fields, toNode(values[key]))
+		}
+		return n
+
+	case reflect.Slice, reflect.Array:
+		n := node{kind: listNode, items: []node{}}
+		for i := 0; i < v.Len(); i++ {
+			n.items = append(n.items, toNode(v.Index(i)))
+		}
+		return n
+
+	case reflect.String:
+		return node{kind: scalarNode, value: v.String(), tag: "!!str"}
+	case reflect.Bool:
+		return node{kind: scalarNode, value: strconv.FormatBool(v.Bool()), tag: "!!bool"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return node{kind: scalarNode, value: strconv.FormatInt(v.Int(), 10), tag: "!!int"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return node{kind: scalarNode, value: strconv.FormatUint(v.Uint(), 10), tag: "!!int"}
+	case reflect.Float32, reflect.Float64:
+		return node{kind: scalarNode, value: strconv.FormatFloat(v.Float(), 'f', -1, 64), tag: "!!float"}
+	default:
+		return node{kind: scalarNode, value: fmt.Sprint(v.Interface()), tag: "!!str"}
+	}
+}
+
+// isEmptyValue mirrors the omitempty rules of encoding/json
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+// inline renders a node on a single line, for table cells and nested values
+func (n node) inline() string {
+	switch n.kind {
+	case objectNode:
+		parts := make([]string, len(n.keys))
+		for i, key := range n.keys {
+			parts[i] = key + ": " + n.fields[i].inline()
+		}
+		return strings.Join(parts, ", ")
+	case listNode:
+		parts := make([]string, len(n.items))
+		for i, item := range n.items {
+			parts[i] = item.inline()
+		}
+		return strings.Join(parts, "; ")
+	default:
+		return n.value
+	}
+}
+
+// flatten turns a list into table columns and rows. Nested objects become
+// dotted columns (e.g. quote.anime); lists are rendered inline.
+func flatten(items []node) ([]string, [][]string) {
+	var columns []string
+	index := make(map[string]int)
+	var rows []map[string]string
+
+	var walk func(n node, prefix string, row map[string]string)
+	walk = func(n node, prefix string, row map[string]string) {
+		if n.kind != objectNode {
+			if prefix == "" {
+				prefix = "value"
+			}
+			if _, ok := index[prefix]; !ok {
+				index[prefix] = len(columns)
+				columns = append(columns, prefix)
+			}
+			row[prefix] = n.inline()
+			return
+		}
+		for i, key := range n.keys {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			walk(n.fields[i], key, row)
+		}
+	}
+
+	for _, item := range items {
+		row := make(map[string]string)
+		walk(item, "", row)
+		rows = append(rows, row)
+	}
+
+	table := make([][]string, len(rows))
+	for i, row := range rows {
+		table[i] = make([]string, len(columns))
+		for j, column := range columns {
+			table[i][j] = row[column]
+		}
+	}
+	return columns, table
+}
+
+// typeColumns returns the table columns flatten produces for entries of
+// type t with every field set, so a table's header does not depend on which
+// omitempty fields its first entry happens to have. It returns false when
+// the columns depend on the values, e.g. for maps.
+func typeColumns(t reflect.Type) ([]string, bool) {
+	var columns []string
+	var walk func(t reflect.Type, prefix string) bool
+	walk = func(t reflect.Type, prefix string) bool {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Interface, reflect.Map:
+			return false
+		case reflect.Struct:
+			for i := 0; i < t.NumField(); i++ {
+				field := t.Field(i)
+				if !field.IsExported() {
+					continue
+				}
+				name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+				if name == "-" {
+					continue
+				}
+				if name == "" {
+					name = field.Name
+				}
+				if prefix != "" {
+					name = prefix + "." + name
+				}
+				if !walk(field.Type, name) {
+					return false
+				}
+			}
+			return true
+		default:
+			if prefix == "" {
+				prefix = "value"
+			}
+			columns = append(columns, prefix)
+			return true
+		}
+	}
+	if !walk(t, "") {
+		return nil, false
+	}
+	return columns, true
+}
+
+// tableList picks the list to render as a table: the response itself, or
+// the first list field of an object response (e.g. search results)
+func tableList(n node) (node, bool) {
+	if n.kind == listNode {
+		return n, true
+	}
+	if n.kind == objectNode {
+		for _, f := range n.fields {
+			if f.kind == listNode {
+				return f, true
+			}
+		}
+	}
+	return n, false
+}
+
+// label turns a JSON field name into a human heading, e.g. quoteCount
+// becomes "Quote Count" and quote.id becomes "Quote ID"
+func label(key string) string {
+	var words []string
+	for _, part := range strings.Split(key, ".") {
+		start := 0
+		runes := []rune(part)
+		for i := 1; i <= len(runes); i++ {
+			if i == len(runes) || unicode.IsUpper(runes[i]) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	for i, w := range words {
+		switch strings.ToLower(w) {
+		case "id", "url":
+			words[i] = strings.ToUpper(w)
+		default:
+			runes := []rune(w)
+			runes[0] = unicode.ToUpper(runes[0])
+			words[i] = string(runes)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// encodeText writes data as "Label: value" lines. List entries are numbered
+// and separated by blank lines.
+func encodeText(w io.Writer, data interface{}) error {
+	var sb strings.Builder
+	writeTextNode(&sb, toNode(reflect.ValueOf(data)), "", true)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeTextNode writes a node at the given indentation. Only the top-level
+// object gets a section per list field; nested lists are written inline.
+func writeTextNode(sb *strings.Builder, n node, indent string, top bool) {
+	switch n.kind {
+	case scalarNode:
+		sb.WriteString(indent + n.value + "\n")
+
+	case listNode:
+		for i, item := range n.items {
+			if item.kind == scalarNode {
+				sb.WriteString(fmt.Sprintf("%s- %s\n", indent, item.value))
+				continue
+			}
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(fmt.Sprintf("%s[%d]\n", indent, i+1))
+			writeTextNode(sb, item, indent, false)
+		}
+
+	case objectNode:
+		// Scalars first so the summary stays at the top, then nested values
+		for i, key := range n.keys {
+			if f := n.fields[i]; f.kind == scalarNode {
+				sb.WriteString(fmt.Sprintf("%s%s: %s\n", indent, label(key), f.value))
+			}
+		}
+		for i, key := range n.keys {
+			f := n.fields[i]
+			switch {
+			case f.kind == objectNode:
+				sb.WriteString(fmt.Sprintf("%s%s:\n", indent, label(key)))
+				writeTextNode(sb, f, indent+"  ", false)
+			case f.kind == listNode && !top:
+				sb.WriteString(fmt.Sprintf("%s%s: %s\n", indent, label(key), f.inline()))
+			case f.kind == listNode:
+				sb.WriteString(fmt.Sprintf("---\n%s (%d):\n\n", label(key), len(f.items)))
+				writeTextNode(sb, f, "", false)
+			}
+		}
+	}
+}
+
+// encodeCSV writes data as a CSV table with a header row
+func encodeCSV(w io.Writer, data interface{}) error {
+	return encodeDelimited(w, data, ',')
+}
+
+// encodeTSV writes data as a tab-separated table with a header row
+func encodeTSV(w io.Writer, data interface{}) error {
+	return encodeDelimited(w, data, '\t')
+}
+
+// encodeDelimited writes data as a delimited table. Object responses with a
+// list field (e.g. search results) are written as that list.
+func encodeDelimited(w io.Writer, data interface{}, comma rune) error {
+	n := toNode(reflect.ValueOf(data))
+	list, ok := tableList(n)
+	if !ok {
+		list = node{kind: listNode, items: []node{n}}
+	}
+
+	columns, rows := flatten(list.items)
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if len(columns) > 0 {
+		cw.Write(columns)
+	}
+	cw.WriteAll(rows)
+	return cw.Error()
+}
+
+// encodeNDJSON writes each entry of a list response as one line of JSON.
+// Other responses are written as a single line.
+func encodeNDJSON(w io.Writer, data interface{}) error {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return json.NewEncoder(w).Encode(data)
+	}
+	enc := json.NewEncoder(w)
+	for i := 0; i < v.Len(); i++ {
+		if err := enc.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeYAML writes data as a YAML document
+func encodeYAML(w io.Writer, data interface{}) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlNode(toNode(reflect.ValueOf(data)))); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// yamlNode converts a node into a YAML node, keeping field order
+func yamlNode(n node) *yaml.Node {
+	switch n.kind {
+	case objectNode:
+		y := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for i, key := range n.keys {
+			y.Content = append(y.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, yamlNode(n.fields[i]))
+		}
+		return y
+	case listNode:
+		y := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range n.items {
+			y.Content = append(y.Content, yamlNode(item))
+		}
+		return y
+	default:
+		value := n.value
+		if n.tag == "!!null" {
+			value = "null"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: n.tag, Value: value}
+	}
+}
+
+// encodeXML writes data as XML under a <response> root. List entries are
+// <item> elements.
+func encodeXML(w io.Writer, data interface{}) error {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	writeXMLNode(&sb, "response", toNode(reflect.ValueOf(data)), "")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeXMLNode writes a node as an element with the given name
+func writeXMLNode(sb *strings.Builder, name string, n node, indent string) {
+	switch n.kind {
+	case scalarNode:
+		sb.WriteString(indent + "<" + name + ">")
+		xml.EscapeText(sb, []byte(n.value))
+		sb.WriteString("</" + name + ">\n")
+	case objectNode:
+		sb.WriteString(indent + "<" + name + ">\n")
+		for i, key := range n.keys {
+			writeXMLNode(sb, key, n.fields[i], indent+"  ")
+		}
+		sb.WriteString(indent + "</" + name + ">\n")
+	case listNode:
+		sb.WriteString(indent + "<" + name + ">\n")
+		for _, item := range n.items {
+			writeXMLNode(sb, "item", item, indent+"  ")
+		}
+		sb.WriteString(indent + "</" + name + ">\n")
+	}
+}
+
+// encodeMarkdown writes data as Markdown. Objects become bullet lists and
+// lists of objects become tables.
+func encodeMarkdown(w io.Writer, data interface{}) error {
+	var sb strings.Builder
+	writeMarkdownNode(&sb, toNode(reflect.ValueOf(data)), 2)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeMarkdownNode writes a node, using the given level for headings
+func writeMarkdownNode(sb *strings.Builder, n node, level int) {
+	switch n.kind {
+	case scalarNode:
+		sb.WriteString(markdownEscape(n.value) + "\n")
+
+	case listNode:
+		if len(n.items) == 0 {
+			sb.WriteString("_None_\n")
+			return
+		}
+		if n.items[0].kind == scalarNode {
+			for _, item := range n.items {
+				sb.WriteString("- " + markdownEscape(item.value) + "\n")
+			}
+			return
+		}
+		columns, rows := flatten(n.items)
+		headers := make([]string, len(columns))
+		for i, column := range columns {
+			headers[i] = label(column)
+		}
+		sb.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+		sb.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = markdownEscape(cell)
+			}
+			sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+
+	case objectNode:
+		for i, key := range n.keys {
+			if f := n.fields[i]; f.kind == scalarNode {
+				sb.WriteString(fmt.Sprintf("- **%s:** %s\n", label(key), markdownEscape(f.value)))
+			}
+		}
+		for i, key := range n.keys {
+			if f := n.fields[i]; f.kind != scalarNode {
+				sb.WriteString(fmt.Sprintf("\n%s %s\n\n", strings.Repeat("#", level), label(key)))
+				writeMarkdownNode(sb, f, level+1)
+			}
+		}
+	}
+}
+
+// markdownEscape keeps a value on one line and stops it from breaking
+// tables or emphasis
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`").Replace(s)
+}
+
+// encodeFortune writes quotes as a fortune(6) file
+func encodeFortune(w io.Writer, data interface{}) error {
+	switch v := data.(type) {
+	case []anime.Quote:
+		return anime.WriteFortunes(w, v)
+	case *anime.Quote:
+		return anime.WriteFortunes(w, []anime.Quote{*v})
+	default:
+		return errUnsupportedData
+	}
+}
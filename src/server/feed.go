@@ -0,0 +1,281 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/apimgr/anime/src/anime"
+)
+
+// feedDays is how many days of quote-of-the-day history the feeds publish
+const feedDays = 30
+
+// feedTitle is the title shared by all feed formats
+const feedTitle = "Anime Quote of the Day"
+
+// feedTagPrefix starts the tag: URIs (RFC 4151) used as feed and entry IDs.
+// IDs must not change with the host a reader happens to fetch the feed
+// from, so unlike links they never use the request's Host header.
+const feedTagPrefix = "tag:anime.apimgr.us,2025:"
+
+// feedItem is one day of the quote-of-the-day history
+type feedItem struct {
+	date  time.Time
+	quote anime.Quote
+	link  string
+}
+
+// feedItems returns the daily quotes for the last feedDays days, newest
+// first. Days are UTC calendar days so every instance publishes the same
+// items.
+func (s *Server) feedItems(base string) []feedItem {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	var items []feedItem
+	for i := 0; i < feedDays; i++ {
+		date := today.AddDate(0, 0, -i)
+		quote := s.animeService.DailyQuote(date)
+		if quote == nil {
+			break
+		}
+		items = append(items, feedItem{
+			date:  date,
+			quote: *quote,
+			link:  base + "/quote/" + quote.ID,
+		})
+	}
+	return items
+}
+
+// title returns the headline for a feed item
+func (item feedItem) title() string {
+	return fmt.Sprintf("%s: %s (%s)", item.date.Format(anime.DateFormat), item.quote.Character, item.quote.Anime)
+}
+
+// summary returns the quote with its attribution as plain text
+func (item feedItem) summary() string {
+	return fmt.Sprintf("%s — %s, %s", item.quote.Quote, item.quote.Character, item.quote.Anime)
+}
+
+// guid returns an ID unique to the day, since the same quote can be picked
+// on more than one day
+func (item feedItem) guid() string {
+	return feedTagPrefix + "daily/" + item.date.Format(anime.DateFormat)
+}
+
+// baseURL returns the scheme and host that feed links point at, preferring
+// the configured FQDN over the request's Host header
+func (s *Server) baseURL(r *http.Request) string {
+	scheme := requestScheme(r)
+	host := r.Host
+	if fqdn := s.config().Server.FQDN; fqdn != "" {
+		host = fqdn
+	}
+	return scheme + "://" + host
+}
+
+// RSS 2.0 document
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// handleFeedRSS publishes the quote-of-the-day history as RSS 2.0
+func (s *Server) handleFeedRSS(w http.ResponseWriter, r *http.Request) {
+	base := s.baseURL(r)
+	items := s.feedItems(base)
+
+	feed := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       feedTitle,
+			Link:        base + "/",
+			Description: "A new anime quote every day",
+			Language:    "en",
+			AtomLink:    rssLink{Href: base + "/feed.rss", Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	for _, item := range items {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       item.title(),
+			Link:        item.link,
+			Description: item.summary(),
+			GUID:        rssGUID{Value: item.guid()},
+			PubDate:     item.date.Format(time.RFC1123Z),
+		})
+	}
+	if len(items) > 0 {
+		feed.Channel.LastBuildDate = items[0].date.Format(time.RFC1123Z)
+	}
+
+	writeFeedXML(w, "application/rss+xml; charset=utf-8", feed)
+}
+
+// Atom 1.0 document
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title     string   `xml:"title"`
+	ID        string   `xml:"id"`
+	Link      atomLink `xml:"link"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+	Content   atomText `xml:"content"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// handleFeedAtom publishes the quote-of-the-day history as Atom
+func (s *Server) handleFeedAtom(w http.ResponseWriter, r *http.Request) {
+	base := s.baseURL(r)
+	items := s.feedItems(base)
+
+	feed := atomFeed{
+		Title: feedTitle,
+		ID:    feedTagPrefix + "daily",
+		Links: []atomLink{
+			{Href: base + "/feed.atom", Rel: "self", Type: "application/atom+xml"},
+			{Href: base + "/", Rel: "alternate", Type: "text/html"},
+		},
+		Author: atomAuthor{Name: "Anime Quotes API"},
+	}
+	for _, item := range items {
+		published := item.date.Format(time.RFC3339)
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:     item.title(),
+			ID:        item.guid(),
+			Link:      atomLink{Href: item.link, Rel: "alternate", Type: "text/html"},
+			Published: published,
+			Updated:   published,
+			Content:   atomText{Type: "text", Value: item.summary()},
+		})
+	}
+	if len(items) > 0 {
+		feed.Updated = items[0].date.Format(time.RFC3339)
+	} else {
+		feed.Updated = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	writeFeedXML(w, "application/atom+xml; charset=utf-8", feed)
+}
+
+// JSON Feed 1.1 document
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string      `json:"id"`
+	URL           string      `json:"url"`
+	Title         string      `json:"title"`
+	ContentText   string      `json:"content_text"`
+	DatePublished string      `json:"date_published"`
+	Quote         anime.Quote `json:"_quote"`
+}
+
+// handleFeedJSON publishes the quote-of-the-day history as a JSON Feed
+func (s *Server) handleFeedJSON(w http.ResponseWriter, r *http.Request) {
+	base := s.baseURL(r)
+	items := s.feedItems(base)
+
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feedTitle,
+		HomePageURL: base + "/",
+		FeedURL:     base + "/feed.json",
+		Description: "A new anime quote every day",
+		Items:       []jsonFeedItem{},
+	}
+	for _, item := range items {
+		feed.Items = append(feed.Items, jsonFeedItem{
+			ID:            item.guid(),
+			URL:           item.link,
+			Title:         item.title(),
+			ContentText:   item.summary(),
+			DatePublished: item.date.Format(time.RFC3339),
+			Quote:         item.quote,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if err := json.NewEncoder(w).Encode(feed); err != nil {
+		log.Printf("Error encoding JSON feed: %v", err)
+	}
+}
+
+// writeFeedXML writes an XML feed document
+func writeFeedXML(w http.ResponseWriter, contentType string, feed interface{}) {
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		log.Printf("Error encoding feed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write([]byte(xml.Header))
+	w.Write(out)
+}
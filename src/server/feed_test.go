@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// feedIDs fetches a feed through the given host and returns its entry IDs
+// and links
+func feedIDs(t *testing.T, s *Server, path, host string) (ids, links []string) {
+	t.Helper()
+	r := httptest.NewRequest("GET", path, nil)
+	r.Host = host
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != 200 {
+		t.Fatalf("GET %s: %d", path, w.Code)
+	}
+
+	switch path {
+	case "/feed.rss":
+		var feed rssFeed
+		if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		for _, item := range feed.Channel.Items {
+			ids = append(ids, item.GUID.Value)
+			links = append(links, item.Link)
+		}
+	case "/feed.atom":
+		var feed struct {
+			ID      string `xml:"id"`
+			Entries []struct {
+				ID   string `xml:"id"`
+				Link struct {
+					Href string `xml:"href,attr"`
+				} `xml:"link"`
+			} `xml:"entry"`
+		}
+		if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		ids = append(ids, feed.ID)
+		for _, entry := range feed.Entries {
+			ids = append(ids, entry.ID)
+			links = append(links, entry.Link.Href)
+		}
+	case "/feed.json":
+		var feed jsonFeed
+		if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		for _, item := range feed.Items {
+			ids = append(ids, item.ID)
+			links = append(links, item.URL)
+		}
+	}
+	return ids, links
+}
+
+func TestFeedIDsIgnoreHost(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, path := range []string{"/feed.rss", "/feed.atom", "/feed.json"} {
+		idsA, linksA := feedIDs(t, s, path, "a.example")
+		idsB, _ := feedIDs(t, s, path, "b.example:8080")
+		if len(idsA) == 0 || !slices.Equal(idsA, idsB) {
+			t.Errorf("%s: IDs differ by host: %q and %q", path, idsA, idsB)
+		}
+		for _, id := range idsA {
+			if !strings.HasPrefix(id, feedTagPrefix) {
+				t.Errorf("%s: ID %q is not a tag: URI", path, id)
+			}
+		}
+		// Links still point at the host the feed was fetched from
+		for _, link := range linksA {
+			if !strings.HasPrefix(link, "http://a.example/quote/") {
+				t.Errorf("%s: link %q", path, link)
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apimgr/anime/src/anime"
+)
+
+// parseFilter reads the anime, character and exclude_anime query parameters.
+// Each parameter may be repeated to match any of several names.
+func parseFilter(r *http.Request) anime.Filter {
+	q := r.URL.Query()
+	return anime.Filter{
+		Anime:        nonEmpty(q["anime"]),
+		Character:    nonEmpty(q["character"]),
+		ExcludeAnime: nonEmpty(q["exclude_anime"]),
+	}
+}
+
+// Search result limits
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// parseSearchParams reads the q and limit query parameters
+func parseSearchParams(r *http.Request) (string, int, error) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		return "", 0, fmt.Errorf("missing search query parameter q")
+	}
+
+	limit := defaultSearchLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			return "", 0, fmt.Errorf("invalid limit %q (must be 1-%d)", v, maxSearchLimit)
+		}
+		limit = n
+	}
+
+	return query, limit, nil
+}
+
+// parseDailyParams reads the date and tz query parameters. The date
+// defaults to today in tz, which defaults to UTC.
+func parseDailyParams(r *http.Request) (time.Time, error) {
+	q := r.URL.Query()
+
+	loc := time.UTC
+	if tz := q.Get("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return time.Time{}, fmt.Errorf("invalid tz %q", tz)
+		}
+	}
+
+	if v := q.Get("date"); v != "" {
+		date, err := time.ParseInLocation(anime.DateFormat, v, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", v)
+		}
+		return date, nil
+	}
+	return time.Now().In(loc), nil
+}
+
+// Suggestion limits
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+// parseSuggestParams reads the prefix and limit query parameters
+func parseSuggestParams(r *http.Request) (string, int, error) {
+	prefix := strings.TrimSpace(r.URL.Query().Get("prefix"))
+	if prefix == "" {
+		return "", 0, fmt.Errorf("missing query parameter prefix")
+	}
+
+	limit := defaultSuggestLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSuggestLimit {
+			return "", 0, fmt.Errorf("invalid limit %q (must be 1-%d)", v, maxSuggestLimit)
+		}
+		limit = n
+	}
+
+	return prefix, limit, nil
+}
+
+// Stream intervals in seconds
+const (
+	defaultStreamInterval = 10
+	minStreamInterval     = 1
+	maxStreamInterval     = 3600
+)
+
+// streamParams holds the options of a quote stream
+type streamParams struct {
+	filter   anime.Filter
+	interval time.Duration
+	seed     string
+	next     int64
+}
+
+// parseStreamParams reads the interval, seed and filter query parameters
+// and the position to resume from. A stream resumes after the event ID in
+// the Last-Event-ID header or last_event_id parameter; otherwise it starts
+// a new sequence with the given seed, or a random one.
+func parseStreamParams(r *http.Request) (streamParams, error) {
+	q := r.URL.Query()
+	p := streamParams{
+		filter:   parseFilter(r),
+		interval: defaultStreamInterval * time.Second,
+		seed:     q.Get("seed"),
+	}
+
+	if v := q.Get("interval"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < minStreamInterval || n > maxStreamInterval {
+			return p, fmt.Errorf("invalid interval %q (must be %d-%d seconds)", v, minStreamInterval, maxStreamInterval)
+		}
+		p.interval = time.Duration(n) * time.Second
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = q.Get("last_event_id")
+	}
+	if lastID != "" {
+		seed, n, err := parseStreamEventID(lastID)
+		if err != nil {
+			return p, err
+		}
+		p.seed, p.next = seed, n+1
+	}
+
+	if p.seed == "" {
+		p.seed = newStreamSeed()
+	}
+	return p, nil
+}
+
+// nonEmpty drops blank values from a query parameter list
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,467 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apimgr/anime/src/anime"
+	"github.com/graphql-go/graphql"
+)
+
+// graphqlRequest is a GraphQL query sent as JSON or as GET parameters
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// quoteConnection is one page of a quote list with its cursors
+type quoteConnection struct {
+	quotes []anime.Quote
+	start  int
+	total  int
+}
+
+// quoteEdge is a quote and the cursor pointing at it
+type quoteEdge struct {
+	cursor string
+	node   anime.Quote
+}
+
+// newGraphQLSchema builds the GraphQL schema over the anime service. The
+// resolvers use the same service methods and indexes as the REST handlers.
+func (s *Server) newGraphQLSchema() (graphql.Schema, error) {
+	quoteType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Quote",
+		Description: "An anime quote",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Description: "Stable ID derived from the quote content"},
+			"anime":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"character": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"quote":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"hasPreviousPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"startCursor":     &graphql.Field{Type: graphql.String},
+			"endCursor":       &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	quoteEdgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "QuoteEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(quoteEdge).cursor, nil },
+			},
+			"node": &graphql.Field{
+				Type:    graphql.NewNonNull(quoteType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(quoteEdge).node, nil },
+			},
+		},
+	})
+
+	quoteConnectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "QuoteConnection",
+		Description: "A page of quotes. Pass endCursor as after to fetch the next page.",
+		Fields: graphql.Fields{
+			"totalCount": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(quoteConnection).total, nil },
+			},
+			"edges": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(quoteEdgeType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(quoteConnection).edges(), nil },
+			},
+			"nodes": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(quoteType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(quoteConnection).quotes, nil },
+			},
+			"pageInfo": &graphql.Field{
+				Type:    graphql.NewNonNull(pageInfoType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(quoteConnection).pageInfo(), nil },
+			},
+		},
+	})
+
+	filterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "QuoteFilter",
+		Description: "Restricts quotes by name. Names are matched leniently, like the REST filters.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"anime":        &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"character":    &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"excludeAnime": &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		},
+	})
+
+	pageArgs := graphql.FieldConfigArgument{
+		"first": &graphql.ArgumentConfig{Type: graphql.Int, Description: fmt.Sprintf("Page size (default %d, max %d)", defaultPerPage, maxPerPage)},
+		"after": &graphql.ArgumentConfig{Type: graphql.String, Description: "Cursor to continue after"},
+	}
+
+	animeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Anime",
+		Fields: graphql.Fields{
+			"slug":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"name":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"quoteCount":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"characterCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	characterType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Character",
+		Fields: graphql.Fields{
+			"slug": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(anime.Character).Slug, nil },
+			},
+			"name": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(anime.Character).Name, nil },
+			},
+			"quoteCount": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "Number of quotes by this character in their anime",
+				Resolve:     func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(anime.Character).QuoteCount, nil },
+			},
+			"anime": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(animeType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					c := p.Source.(anime.Character)
+					if len(c.Anime) > 0 {
+						return c.Anime, nil
+					}
+					if full, ok := s.animeService.GetCharacter(c.Slug); ok {
+						return full.Anime, nil
+					}
+					return []anime.Anime{}, nil
+				},
+			},
+			"quotes": &graphql.Field{
+				Type:        graphql.NewNonNull(quoteConnectionType),
+				Description: "Quotes by this character in their anime",
+				Args:        pageArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					c := p.Source.(anime.Character)
+					if c.Quotes == nil {
+						if full, ok := s.animeService.GetCharacter(c.Slug); ok {
+							c = *full
+						}
+					}
+					return paginateQuotes(c.Quotes, p.Args)
+				},
+			},
+		},
+	})
+
+	animeType.AddFieldConfig("characters", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(characterType))),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			a := p.Source.(anime.Anime)
+			if a.Characters == nil {
+				if full, ok := s.animeService.GetAnime(a.Slug); ok {
+					a = *full
+				}
+			}
+			return a.Characters, nil
+		},
+	})
+	animeType.AddFieldConfig("quotes", &graphql.Field{
+		Type: graphql.NewNonNull(quoteConnectionType),
+		Args: pageArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			a := p.Source.(anime.Anime)
+			return paginateQuotes(s.animeService.FilterQuotes(anime.Filter{Anime: []string{a.Name}}), p.Args)
+		},
+	})
+
+	statsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Stats",
+		Fields: graphql.Fields{
+			"totalQuotes":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"totalAnime":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"totalCharacters": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"uptime":          &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"quote": &graphql.Field{
+				Type:        quoteType,
+				Description: "A quote by ID, or null if no quote has that ID",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if quote, ok := s.animeService.GetQuoteByID(p.Args["id"].(string)); ok {
+						return *quote, nil
+					}
+					return nil, nil
+				},
+			},
+			"quotes": &graphql.Field{
+				Type:        graphql.NewNonNull(quoteConnectionType),
+				Description: "Quotes matching the filter",
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: filterType},
+					"first":  pageArgs["first"],
+					"after":  pageArgs["after"],
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return paginateQuotes(s.animeService.FilterQuotes(graphqlFilter(p.Args)), p.Args)
+				},
+			},
+			"random": &graphql.Field{
+				Type:        quoteType,
+				Description: "A random quote matching the filter, or null if none match. The same seed always picks the same quote.",
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: filterType},
+					"seed":   &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var quote *anime.Quote
+					if seed, ok := p.Args["seed"].(string); ok && seed != "" {
+						quote = s.animeService.SequenceQuote(graphqlFilter(p.Args), seed, 0)
+					} else {
+						quote = s.animeService.GetRandomQuoteFiltered(graphqlFilter(p.Args))
+					}
+					if quote == nil {
+						return nil, nil
+					}
+					return *quote, nil
+				},
+			},
+			"anime": &graphql.Field{
+				Type:        animeType,
+				Description: "An anime by slug, or null if none matches",
+				Args: graphql.FieldConfigArgument{
+					"slug": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if a, ok := s.animeService.GetAnime(p.Args["slug"].(string)); ok {
+						return *a, nil
+					}
+					return nil, nil
+				},
+			},
+			"animeList": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(animeType))),
+				Description: "Every anime sorted by name",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.animeService.ListAnime(), nil
+				},
+			},
+			"character": &graphql.Field{
+				Type:        characterType,
+				Description: "A character by slug, or null if none matches",
+				Args: graphql.FieldConfigArgument{
+					"slug": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if c, ok := s.animeService.GetCharacter(p.Args["slug"].(string)); ok {
+						return *c, nil
+					}
+					return nil, nil
+				},
+			},
+			"characters": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(characterType))),
+				Description: "Every character sorted by name",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.animeService.ListCharacters(), nil
+				},
+			},
+			"stats": &graphql.Field{
+				Type: graphql.NewNonNull(statsType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return map[string]interface{}{
+						"totalQuotes":     s.animeService.GetTotalQuotes(),
+						"totalAnime":      s.animeService.GetTotalAnime(),
+						"totalCharacters": s.animeService.GetTotalCharacters(),
+						"uptime":          time.Since(s.startTime).String(),
+					}, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// graphqlFilter converts the filter argument into an anime.Filter
+func graphqlFilter(args map[string]interface{}) anime.Filter {
+	input, _ := args["filter"].(map[string]interface{})
+	return anime.Filter{
+		Anime:        graphqlStrings(input["anime"]),
+		Character:    graphqlStrings(input["character"]),
+		ExcludeAnime: graphqlStrings(input["excludeAnime"]),
+	}
+}
+
+// graphqlStrings converts a list argument into non-empty strings
+func graphqlStrings(value interface{}) []string {
+	list, _ := value.([]interface{})
+	var result []string
+	for _, v := range list {
+		if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// paginateQuotes returns the page of quotes selected by the first and
+// after arguments
+func paginateQuotes(quotes []anime.Quote, args map[string]interface{}) (quoteConnection, error) {
+	first := defaultPerPage
+	if v, ok := args["first"].(int); ok {
+		if v < 0 || v > maxPerPage {
+			return quoteConnection{}, fmt.Errorf("invalid first %d (must be 0-%d)", v, maxPerPage)
+		}
+		first = v
+	}
+
+	start := 0
+	if after, ok := args["after"].(string); ok && after != "" {
+		offset, err := decodeCursor(after)
+		if err != nil {
+			return quoteConnection{}, err
+		}
+		start = min(offset+1, len(quotes))
+	}
+
+	end := min(start+first, len(quotes))
+	return quoteConnection{quotes: quotes[start:end], start: start, total: len(quotes)}, nil
+}
+
+// edges pairs every quote on the page with its cursor
+func (c quoteConnection) edges() []quoteEdge {
+	edges := make([]quoteEdge, len(c.quotes))
+	for i, q := range c.quotes {
+		edges[i] = quoteEdge{cursor: encodeCursor(c.start + i), node: q}
+	}
+	return edges
+}
+
+// pageInfo describes the position of the page in the full list
+func (c quoteConnection) pageInfo() map[string]interface{} {
+	info := map[string]interface{}{
+		"hasNextPage":     c.start+len(c.quotes) < c.total,
+		"hasPreviousPage": c.start > 0,
+		"startCursor":     nil,
+		"endCursor":       nil,
+	}
+	if len(c.quotes) > 0 {
+		info["startCursor"] = encodeCursor(c.start)
+		info["endCursor"] = encodeCursor(c.start + len(c.quotes) - 1)
+	}
+	return info
+}
+
+// encodeCursor returns the opaque cursor for a list position
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("quote:" + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the list position a cursor points at
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), "quote:")); err == nil && offset >= 0 && strings.HasPrefix(string(raw), "quote:") {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q", cursor)
+}
+
+// handleGraphQL executes a GraphQL query sent as a POST body (JSON or
+// application/graphql) or as GET query parameters
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	req, err := parseGraphQLRequest(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"errors": []map[string]string{{"message": err.Error()}},
+		})
+		return
+	}
+
+	listSizes := map[string]int{
+		"Query.animeList":  s.animeService.GetTotalAnime(),
+		"Query.characters": s.animeService.GetTotalCharacters(),
+	}
+	if err := checkGraphQLCost(s.graphqlSchema, listSizes, req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"errors": []map[string]string{{"message": err.Error()}},
+		})
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         s.graphqlSchema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        r.Context(),
+	})
+	if len(result.Errors) > 0 {
+		log.Printf("GraphQL query returned %d error(s): %v", len(result.Errors), result.Errors[0].Message)
+	}
+	respondJSON(w, http.StatusOK, result)
+}
+
+// parseGraphQLRequest reads the query, variables and operation name
+func parseGraphQLRequest(r *http.Request) (graphqlRequest, error) {
+	var req graphqlRequest
+
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return req, fmt.Errorf("invalid variables: %v", err)
+			}
+		}
+	} else if strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql") {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return req, fmt.Errorf("failed to read request body: %v", err)
+		}
+		req.Query = string(body)
+	} else if err := parseJSON(r, &req); err != nil {
+		return req, fmt.Errorf("invalid JSON body: %v", err)
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		return req, fmt.Errorf("missing query (open /graphiql to explore the schema)")
+	}
+	return req, nil
+}
+
+// handleExplorer renders the in-browser GraphQL explorer
+func (s *Server) handleExplorer(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title": "GraphQL Explorer",
+		"Page":  "explorer",
+		"Theme": s.config().WebUI.Theme,
+	}
+	if err := renderPage(w, http.StatusOK, "explorer", data); err != nil {
+		log.Printf("Error rendering template: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// explorerIntrospection is the schema query the explorer page sends
+const explorerIntrospection = `{
+  __schema {
+    queryType { name }
+    types {
+      name kind description
+      fields { name description args { name type { ...TypeRef } } type { ...TypeRef } }
+      inputFields { name type { ...TypeRef } }
+    }
+  }
+}
+fragment TypeRef on __Type {
+  kind name
+  ofType { kind name ofType { kind name ofType { kind name } } }
+}`
+
+// fullIntrospection is the introspection query of common GraphQL tools
+const fullIntrospection = `query IntrospectionQuery {
+  __schema {
+    queryType { name } mutationType { name } subscriptionType { name }
+    types { ...FullType }
+    directives { name description locations args { ...InputValue } }
+  }
+}
+fragment FullType on __Type {
+  kind name description
+  fields(includeDeprecated: true) { name description args { ...InputValue } type { ...TypeRef } isDeprecated deprecationReason }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) { name description isDeprecated deprecationReason }
+  possibleTypes { ...TypeRef }
+}
+fragment InputValue on __InputValue { name description type { ...TypeRef } defaultValue }
+fragment TypeRef on __Type {
+  kind name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
+
+func TestCheckGraphQLCost(t *testing.T) {
+	s := newTestServer(t)
+	sizes := map[string]int{"Query.animeList": 500, "Query.characters": 2000}
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		wantErr   string
+	}{
+		{"explorer introspection", explorerIntrospection, nil, ""},
+		{"full introspection", fullIntrospection, nil, ""},
+		{"nested sample", `{ anime(slug: "naruto") { name characters { name quotes(first: 2) { nodes { quote } } } } }`, nil, ""},
+		{"one large page", `{ quotes(first: 500) { edges { cursor node { id anime character quote } } } }`, nil, ""},
+		{"every character", `{ characters { name quoteCount } }`, nil, ""},
+		{"pages of every character", `{ characters { quotes(first: 500) { nodes { quote } } } }`, nil, "complexity"},
+		{"default pages of every anime", `{ animeList { quotes { nodes { id quote } } } }`, nil, "complexity"},
+		{"page size from a variable", `query($n: Int) { anime(slug: "x") { characters { quotes(first: $n) { nodes { quote } } } } }`, map[string]interface{}{"n": float64(500)}, ""},
+		{"fan-out", `{ anime(slug: "x") { characters { anime { characters { anime { characters { anime { name } } } } } } } }`, nil, "complexity"},
+		{"fan-out through fragments", `{ anime(slug: "x") { ...A } } fragment A on Anime { characters { anime { characters { anime { characters { anime { characters { name } } } } } } } }`, nil, "complexity"},
+		{"too deep", "{ __schema { types" + strings.Repeat(" { fields { type", 8) + " { name" + strings.Repeat(" }", 19), nil, "levels deep"},
+		{"fragment cycle", `{ anime(slug: "x") { ...A } } fragment A on Anime { characters { anime { ...A } } }`, nil, ""},
+		{"named operation", `query Small { stats { totalQuotes } } query Big { characters { quotes(first: 500) { nodes { quote } } } }`, nil, ""},
+		{"syntax error", `{ anime(`, nil, ""},
+	}
+	for _, tt := range tests {
+		req := graphqlRequest{Query: tt.query, Variables: tt.variables}
+		if tt.name == "named operation" {
+			req.OperationName = "Small"
+		}
+		err := checkGraphQLCost(s.graphqlSchema, sizes, req)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: error %v, want one mentioning %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGraphQLRejectsExpensiveQuery(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{`{ stats { totalQuotes } }`, http.StatusOK},
+		{explorerIntrospection, http.StatusOK},
+		{`{ anime(slug: "naruto") { characters { anime { characters { anime { characters { anime { characters { name } } } } } } } } }`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		body, _ := json.Marshal(graphqlRequest{Query: tt.query})
+		r := httptest.NewRequest("POST", "/api/graphql", strings.NewReader(string(body)))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		var result struct {
+			Data   map[string]interface{} `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("%q: %v", w.Body.String(), err)
+		}
+		if w.Code != tt.status || (tt.status == http.StatusOK) != (len(result.Errors) == 0) {
+			t.Errorf("query %.40q: %d %s, want %d", tt.query, w.Code, w.Body.String(), tt.status)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+const (
+	// graphqlMaxDepth is how deeply selections may nest. It leaves room
+	// for the usual introspection queries, which follow ofType chains
+	// about a dozen levels down.
+	graphqlMaxDepth = 15
+
+	// graphqlMaxComplexity caps the estimated number of fields a query
+	// resolves, so nested lists such as anime → characters → anime cannot
+	// fan out without bound
+	graphqlMaxComplexity = 50000
+
+	// graphqlListEstimate is the size assumed for lists without a first
+	// argument when estimating complexity
+	graphqlListEstimate = 10
+)
+
+// graphqlCost walks a query document to estimate its depth and complexity
+// before it runs. Each field costs one per time it is expected to be
+// resolved: fields with a first argument multiply their selections by it,
+// lists with a known size (e.g. Query.characters) by that size and other
+// lists by graphqlListEstimate.
+type graphqlCost struct {
+	schema    graphql.Schema
+	listSizes map[string]int
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// checkGraphQLCost rejects queries deeper than graphqlMaxDepth or more
+// complex than graphqlMaxComplexity. listSizes maps "Type.field" to the
+// length of the list the field returns. Documents that fail to parse are
+// left for graphql.Do to report.
+func checkGraphQLCost(schema graphql.Schema, listSizes map[string]int, req graphqlRequest) error {
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return nil
+	}
+
+	c := graphqlCost{
+		schema:    schema,
+		listSizes: listSizes,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: req.Variables,
+	}
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			c.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if req.OperationName == "" || (def.Name != nil && def.Name.Value == req.OperationName) {
+				operations = append(operations, def)
+			}
+		}
+	}
+
+	for _, op := range operations {
+		root := schema.QueryType()
+		if op.Operation == ast.OperationTypeMutation {
+			root = schema.MutationType()
+		}
+		if root == nil {
+			continue
+		}
+		complexity, depth := c.selectionSet(root, op.SelectionSet, 1, map[string]bool{})
+		if depth > graphqlMaxDepth {
+			return fmt.Errorf("query is nested %d levels deep (max %d)", depth, graphqlMaxDepth)
+		}
+		if complexity > graphqlMaxComplexity {
+			return fmt.Errorf("query complexity %d exceeds the limit of %d; request fewer fields or smaller pages", complexity, graphqlMaxComplexity)
+		}
+	}
+	return nil
+}
+
+// selectionSet returns the complexity of a selection set on parent and the
+// depth of its deepest field. visiting guards against fragment cycles,
+// which validation rejects later.
+func (c graphqlCost) selectionSet(parent *graphql.Object, set *ast.SelectionSet, depth int, visiting map[string]bool) (int, int) {
+	if set == nil {
+		return 0, depth - 1
+	}
+
+	complexity, maxDepth := 0, depth
+	add := func(cost, d int) {
+		complexity = min(complexity+cost, graphqlMaxComplexity+1)
+		maxDepth = max(maxDepth, d)
+	}
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			add(c.field(parent, sel, depth, visiting))
+		case *ast.InlineFragment:
+			add(c.selectionSet(c.fragmentType(parent, sel.TypeCondition), sel.SelectionSet, depth, visiting))
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			frag, ok := c.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			add(c.selectionSet(c.fragmentType(parent, frag.TypeCondition), frag.SelectionSet, depth, visiting))
+			delete(visiting, name)
+		}
+	}
+	return complexity, maxDepth
+}
+
+// field returns the complexity and depth of one field and its selections
+func (c graphqlCost) field(parent *graphql.Object, f *ast.Field, depth int, visiting map[string]bool) (int, int) {
+	var def *graphql.FieldDefinition
+	switch name := f.Name.Value; name {
+	case "__typename":
+		return 1, depth
+	case "__schema":
+		def = graphql.SchemaMetaFieldDef
+	case "__type":
+		def = graphql.TypeMetaFieldDef
+	default:
+		def = parent.Fields()[name]
+	}
+	if def == nil {
+		return 1, depth
+	}
+
+	fieldType, list := namedType(def.Type)
+	object, ok := fieldType.(*graphql.Object)
+	if !ok || f.SelectionSet == nil {
+		return 1, depth
+	}
+
+	multiplier := 1
+	if first, ok := c.intArgument(f, "first"); ok {
+		multiplier = max(first, 0)
+	} else if hasArgument(def, "first") {
+		multiplier = defaultPerPage
+	} else if size, ok := c.listSizes[parent.Name()+"."+def.Name]; ok {
+		multiplier = size
+	} else if list && !strings.HasSuffix(parent.Name(), "Connection") {
+		// A connection's lists are sized by the field's first argument
+		multiplier = graphqlListEstimate
+	}
+
+	complexity, d := c.selectionSet(object, f.SelectionSet, depth+1, visiting)
+	return min(1+multiplier*complexity, graphqlMaxComplexity+1), d
+}
+
+// fragmentType returns the object type a fragment applies to
+func (c graphqlCost) fragmentType(parent *graphql.Object, condition *ast.Named) *graphql.Object {
+	if condition != nil {
+		if object, ok := c.schema.Type(condition.Name.Value).(*graphql.Object); ok {
+			return object
+		}
+	}
+	return parent
+}
+
+// intArgument returns the value of an integer argument given literally or
+// through a variable
+func (c graphqlCost) intArgument(f *ast.Field, name string) (int, bool) {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != name {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			n, err := strconv.Atoi(v.Value)
+			return n, err == nil
+		case *ast.Variable:
+			// JSON numbers decode as float64
+			if n, ok := c.variables[v.Name.Value].(float64); ok {
+				return int(n), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// hasArgument reports whether a field takes the named argument
+func hasArgument(def *graphql.FieldDefinition, name string) bool {
+	for _, arg := range def.Args {
+		if arg.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// namedType unwraps non-null and list wrappers, reporting whether there
+// was a list among them
+func namedType(t graphql.Type) (graphql.Type, bool) {
+	list := false
+	for {
+		switch wrapped := t.(type) {
+		case *graphql.NonNull:
+			t = wrapped.OfType
+		case *graphql.List:
+			t, list = wrapped.OfType, true
+		default:
+			return t, list
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/apimgr/anime/src/anime"
+	"github.com/apimgr/anime/src/animepb"
+	"github.com/go-chi/httprate"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// grpcService implements animepb.AnimeQuotesServer over the anime service
+type grpcService struct {
+	animepb.UnimplementedAnimeQuotesServer
+	animeService *anime.Service
+}
+
+// newGRPCServer creates the gRPC server with the quote service, the
+// standard health service and, if withReflection is set, reflection for
+// tools like grpcurl. Incoming messages are capped at maxMsgSize bytes.
+// Calls are limited and logged like HTTP API requests.
+func (s *Server) newGRPCServer(maxMsgSize int, withReflection bool) (*grpc.Server, *health.Server) {
+	srv := grpc.NewServer(
+		grpc.MaxRecvMsgSize(maxMsgSize),
+		grpc.UnaryInterceptor(s.grpcUnaryInterceptor),
+		grpc.StreamInterceptor(s.grpcStreamInterceptor),
+	)
+	animepb.RegisterAnimeQuotesServer(srv, &grpcService{animeService: s.animeService})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(animepb.AnimeQuotes_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthServer)
+
+	if withReflection {
+		reflection.Register(srv)
+	}
+	return srv, healthServer
+}
+
+// GetQuote returns a quote by ID
+func (g *grpcService) GetQuote(ctx context.Context, req *animepb.GetQuoteRequest) (*animepb.Quote, error) {
+	quote, ok := g.animeService.GetQuoteByID(req.GetId())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "quote %q not found", req.GetId())
+	}
+	return toProtoQuote(*quote), nil
+}
+
+// Random returns a random quote matching the filter, or the one the seed
+// picks
+func (g *grpcService) Random(ctx context.Context, req *animepb.RandomRequest) (*animepb.Quote, error) {
+	filter := fromProtoFilter(req.GetFilter())
+
+	var quote *anime.Quote
+	if req.GetSeed() != "" {
+		quote = g.animeService.SequenceQuote(filter, req.GetSeed(), 0)
+	} else {
+		quote = g.animeService.GetRandomQuoteFiltered(filter)
+	}
+	if quote == nil {
+		return nil, status.Error(codes.NotFound, "no quotes match the given filters")
+	}
+	return toProtoQuote(*quote), nil
+}
+
+// Search runs a full-text search over quotes
+func (g *grpcService) Search(ctx context.Context, req *animepb.SearchRequest) (*animepb.SearchResponse, error) {
+	query := strings.TrimSpace(req.GetQuery())
+	if query == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing search query")
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit %d (must be 1-%d)", limit, maxSearchLimit)
+	}
+
+	results, total := g.animeService.Search(query, fromProtoFilter(req.GetFilter()), limit)
+	resp := &animepb.SearchResponse{Total: int32(total)}
+	for _, result := range results {
+		r := &animepb.SearchResult{Quote: toProtoQuote(result.Quote), Score: result.Score}
+		for _, h := range result.Highlights {
+			r.Highlights = append(r.Highlights, &animepb.Highlight{Field: h.Field, Start: int32(h.Start), End: int32(h.End)})
+		}
+		resp.Results = append(resp.Results, r)
+	}
+	return resp, nil
+}
+
+// ListQuotes streams every quote matching the filter
+func (g *grpcService) ListQuotes(req *animepb.ListQuotesRequest, stream grpc.ServerStreamingServer[animepb.Quote]) error {
+	quotes := g.animeService.FilterQuotes(fromProtoFilter(req.GetFilter()))
+	if req.GetSort() != "" {
+		if err := anime.SortQuotes(quotes, req.GetSort()); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	for _, q := range quotes {
+		if err := stream.Send(toProtoQuote(q)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toProtoQuote converts a quote to its protobuf message
+func toProtoQuote(q anime.Quote) *animepb.Quote {
+	return &animepb.Quote{Id: q.ID, Anime: q.Anime, Character: q.Character, Quote: q.Quote}
+}
+
+// fromProtoFilter converts a protobuf filter, which may be nil
+func fromProtoFilter(f *animepb.Filter) anime.Filter {
+	return anime.Filter{
+		Anime:        nonEmpty(f.GetAnime()),
+		Character:    nonEmpty(f.GetCharacter()),
+		ExcludeAnime: nonEmpty(f.GetExcludeAnime()),
+	}
+}
+
+// grpcUnaryInterceptor applies the HTTP API's limits to unary calls and
+// logs them
+func (s *Server) grpcUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	s.grpcActive.Add(1)
+	defer s.grpcActive.Add(-1)
+	start := time.Now()
+	ip, release, err := s.admitGRPC(ctx, info.FullMethod)
+	var resp interface{}
+	if err == nil {
+		resp, err = handler(ctx, req)
+		release()
+	}
+	logGRPC(ip, info.FullMethod, err, start)
+	return resp, err
+}
+
+// grpcStreamInterceptor applies the HTTP API's limits to streaming calls
+// and logs them. A stream holds its concurrency slot until it ends.
+func (s *Server) grpcStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	s.grpcActive.Add(1)
+	defer s.grpcActive.Add(-1)
+	start := time.Now()
+	ip, release, err := s.admitGRPC(ss.Context(), info.FullMethod)
+	if err == nil {
+		err = handler(srv, ss)
+		release()
+	}
+	logGRPC(ip, info.FullMethod, err, start)
+	return err
+}
+
+// admitGRPC resolves the caller's address and checks the concurrency cap
+// and the global and API rate limits shared with HTTP requests. On success
+// the caller must call release when the call ends. The rate limit headers
+// are sent as response metadata.
+func (s *Server) admitGRPC(ctx context.Context, method string) (netip.Addr, func(), error) {
+	state := s.limitState.Load()
+	r := grpcRequest(ctx, method, state.trusted)
+	ip := clientIP(r)
+
+	select {
+	case state.inFlight <- struct{}{}:
+	default:
+		log.Printf("Too many concurrent requests, rejecting: GRPC %s", method)
+		return ip, nil, status.Error(codes.Unavailable, "too many concurrent requests")
+	}
+	release := func() { <-state.inFlight }
+
+	key, _ := rateLimitKey(r)
+	w := &headerRecorder{header: http.Header{}}
+	for _, limiter := range []*httprate.RateLimiter{state.global, state.api} {
+		if limiter.OnLimit(w, r, key) {
+			release()
+			grpc.SetHeader(ctx, headerMetadata(w.header))
+			return ip, nil, status.Error(codes.ResourceExhausted, "rate limit exceeded, retry later")
+		}
+	}
+	grpc.SetHeader(ctx, headerMetadata(w.header))
+	return ip, release, nil
+}
+
+// grpcRequest describes a gRPC call as an HTTP request from the peer with
+// the call's metadata as headers, so the client address is resolved
+// through the trusted proxies and rate limited like an HTTP request
+func grpcRequest(ctx context.Context, method string, trusted []netip.Prefix) *http.Request {
+	r := &http.Request{
+		Method:     http.MethodPost,
+		URL:        &url.URL{Path: method},
+		RequestURI: method,
+		Header:     http.Header{},
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			for _, value := range values {
+				r.Header.Add(key, value)
+			}
+		}
+	}
+	ip, _ := resolveClient(r, trusted)
+	return r.WithContext(context.WithValue(ctx, clientIPKey{}, ip))
+}
+
+// headerRecorder collects the headers httprate sets on a response
+type headerRecorder struct {
+	header http.Header
+}
+
+func (h *headerRecorder) Header() http.Header         { return h.header }
+func (h *headerRecorder) Write(b []byte) (int, error) { return len(b), nil }
+func (h *headerRecorder) WriteHeader(int)             {}
+
+// headerMetadata converts HTTP headers to gRPC metadata
+func headerMetadata(h http.Header) metadata.MD {
+	md := metadata.MD{}
+	for key, values := range h {
+		md.Append(key, values...)
+	}
+	return md
+}
+
+// logGRPC logs a finished call like loggingMiddleware logs HTTP requests
+func logGRPC(ip netip.Addr, method string, err error, start time.Time) {
+	log.Printf("%s GRPC %s %s %s", ip, method, status.Code(err), time.Since(start))
+}
+
+// withGRPC routes HTTP/2 requests with a gRPC content type to the gRPC
+// server and everything else to the HTTP handler. h2c lets plaintext
+// gRPC clients share the HTTP port; h2s serves those connections.
+func withGRPC(grpcServer *grpc.Server, handler http.Handler, h2s *http2.Server) http.Handler {
+	return h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}), h2s)
+}
+
+// serveGRPC serves gRPC on its own port
+func serveGRPC(grpcServer *grpc.Server, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return grpcServer.Serve(lis)
+}
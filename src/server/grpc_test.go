@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apimgr/anime/src/animepb"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcTarget serves the server's gRPC service on its own listener or, when
+// shared is set, on the HTTP handler over h2c, and returns the address.
+// Shutdown stops it like a server run by Start.
+func grpcTarget(t *testing.T, s *Server, shared bool) string {
+	t.Helper()
+	grpcServer, grpcHealth := s.newGRPCServer(1<<20, false)
+	s.serveMu.Lock()
+	s.grpcServer, s.grpcHealth, s.grpcShared = grpcServer, grpcHealth, shared
+	s.serveMu.Unlock()
+	if shared {
+		ts := httptest.NewServer(withGRPC(grpcServer, s, &http2.Server{}))
+		t.Cleanup(ts.Close)
+		return strings.TrimPrefix(ts.URL, "http://")
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+	return lis.Addr().String()
+}
+
+// grpcClient connects to a gRPC target
+func grpcClient(t *testing.T, target string) animepb.AnimeQuotesClient {
+	t.Helper()
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return animepb.NewAnimeQuotesClient(conn)
+}
+
+// listAll reads a whole ListQuotes stream
+func listAll(ctx context.Context, client animepb.AnimeQuotesClient) error {
+	stream, err := client.ListQuotes(ctx, &animepb.ListQuotesRequest{})
+	if err != nil {
+		return err
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func TestGRPCLimits(t *testing.T) {
+	for _, shared := range []bool{false, true} {
+		s := newTestServer(t)
+		cfg := *s.config()
+		cfg.Server.Limits.APIRPS = 3
+		cfg.Server.Limits.GlobalRPS = 100
+		cfg.Server.TrustedProxies = []string{"127.0.0.1/32"}
+		s.setConfig(&cfg)
+		client := grpcClient(t, grpcTarget(t, s, shared))
+		ctx := context.Background()
+
+		// Every call holds a concurrency slot, shared with HTTP requests
+		state := s.limitState.Load()
+		for i := 0; i < cap(state.inFlight); i++ {
+			state.inFlight <- struct{}{}
+		}
+		_, err := client.Random(ctx, &animepb.RandomRequest{})
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("shared %v, at max_concurrent: %v, want Unavailable", shared, err)
+		}
+		for i := 0; i < cap(state.inFlight); i++ {
+			<-state.inFlight
+		}
+
+		// The API rate limit covers unary and streaming calls and reports
+		// the budget in the response metadata
+		var header metadata.MD
+		if _, err := client.Random(ctx, &animepb.RandomRequest{}, grpc.Header(&header)); err != nil {
+			t.Fatalf("shared %v: Random: %v", shared, err)
+		}
+		if got := header.Get("x-ratelimit-limit"); len(got) != 1 || got[0] != "3" {
+			t.Errorf("shared %v: x-ratelimit-limit = %q, want 3", shared, got)
+		}
+		if err := listAll(ctx, client); err != nil {
+			t.Fatalf("shared %v: ListQuotes: %v", shared, err)
+		}
+
+		// HTTP API requests from the same client use the same budget
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/random", nil)
+		r.RemoteAddr = "127.0.0.1:1234"
+		s.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("shared %v: HTTP request: %d", shared, w.Code)
+		}
+		if err := listAll(ctx, client); status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("shared %v, over the rate limit: %v, want ResourceExhausted", shared, err)
+		}
+
+		// A client behind a trusted proxy has its own budget
+		fwd := metadata.AppendToOutgoingContext(ctx, "x-forwarded-for", "198.51.100.7")
+		if _, err := client.Random(fwd, &animepb.RandomRequest{}); err != nil {
+			t.Errorf("shared %v, forwarded client: %v", shared, err)
+		}
+	}
+}
+
+// syncBuffer collects log output written from server goroutines
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestGRPCLogging(t *testing.T) {
+	var logs syncBuffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	s := newTestServer(t)
+	cfg := *s.config()
+	cfg.Server.TrustedProxies = []string{"127.0.0.0/8"}
+	s.setConfig(&cfg)
+	client := grpcClient(t, grpcTarget(t, s, false))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-forwarded-for", "198.51.100.7")
+	client.GetQuote(ctx, &animepb.GetQuoteRequest{Id: "missing"})
+	if want := "198.51.100.7 GRPC /anime.v1.AnimeQuotes/GetQuote NotFound"; !strings.Contains(logs.String(), want) {
+		t.Errorf("log %q does not contain %q", logs.String(), want)
+	}
+}
+
+func TestGRPCShutdownHealth(t *testing.T) {
+	for _, shared := range []bool{false, true} {
+		s := newTestServer(t)
+		conn, err := grpc.NewClient(grpcTarget(t, s, shared), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		watch, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp, err := watch.Recv(); err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+			t.Fatalf("shared %v, before shutdown: %v %v, want SERVING", shared, resp, err)
+		}
+
+		// The open Watch stream keeps Shutdown waiting until ctx is done
+		done := make(chan error, 1)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			done <- s.Shutdown(ctx)
+		}()
+		if resp, err := watch.Recv(); err != nil || resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("shared %v, during shutdown: %v %v, want NOT_SERVING", shared, resp, err)
+		}
+		if err := <-done; err != context.DeadlineExceeded {
+			t.Errorf("shared %v: Shutdown returned %v, want the deadline error", shared, err)
+		}
+	}
+}
+
+func TestGRPCReflectionOptIn(t *testing.T) {
+	s := newTestServer(t)
+	for _, withReflection := range []bool{false, true} {
+		grpcServer, _ := s.newGRPCServer(1<<20, withReflection)
+		_, registered := grpcServer.GetServiceInfo()["grpc.reflection.v1.ServerReflection"]
+		if registered != withReflection {
+			t.Errorf("withReflection %v: reflection registered = %v", withReflection, registered)
+		}
+	}
+}
@@ -6,20 +6,154 @@ import (
 	"log"
 	"net/http"
 	"runtime"
-	"strings"
+	"strconv"
 	"time"
+
+	"github.com/apimgr/anime/src/anime"
+	"github.com/gorilla/mux"
 )
 
-// handleRandomQuote returns a random anime quote
+// handleRandomQuote returns a random anime quote. With ?seed= the pick is
+// deterministic, so the same seed always returns the same quote.
 func (s *Server) handleRandomQuote(w http.ResponseWriter, r *http.Request) {
-	quote := s.animeService.GetRandomQuote()
-	respondJSON(w, http.StatusOK, quote)
+	filter := parseFilter(r)
+	var quote *anime.Quote
+	if seed := r.URL.Query().Get("seed"); seed != "" {
+		quote = s.animeService.SequenceQuote(filter, seed, 0)
+	} else {
+		quote = s.animeService.GetRandomQuoteFiltered(filter)
+	}
+	if quote == nil {
+		respondError(w, r, http.StatusNotFound, "no quotes match the given filters")
+		return
+	}
+	s.respondQuote(w, r, quote)
+}
+
+// handleDaily returns the quote of the day
+func (s *Server) handleDaily(w http.ResponseWriter, r *http.Request) {
+	date, err := parseDailyParams(r)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	quote := s.animeService.DailyQuote(date)
+	if quote == nil {
+		respondError(w, r, http.StatusNotFound, "no quotes loaded")
+		return
+	}
+	w.Header().Set("X-Quote-Date", date.Format(anime.DateFormat))
+	s.respondQuote(w, r, quote)
 }
 
 // handleAllQuotes returns all anime quotes
 func (s *Server) handleAllQuotes(w http.ResponseWriter, r *http.Request) {
-	quotes := s.animeService.GetAllQuotes()
-	respondJSON(w, http.StatusOK, quotes)
+	params, err := parseListParams(r)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := parseFilter(r)
+	quotes := s.animeService.FilterQuotes(filter)
+	if len(quotes) == 0 && !filter.IsEmpty() {
+		respondError(w, r, http.StatusNotFound, "no quotes match the given filters")
+		return
+	}
+
+	total := len(quotes)
+	page, err := params.apply(quotes)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params.setPaginationHeaders(w, r, total)
+	respond(w, r, http.StatusOK, params.project(page))
+}
+
+// handleQuote returns a single quote by ID
+func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	quote, ok := s.animeService.GetQuoteByID(id)
+	if !ok {
+		respondError(w, r, http.StatusNotFound, "quote not found")
+		return
+	}
+	s.respondQuote(w, r, quote)
+}
+
+// handleSearch runs a full-text search over quotes
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query, limit, err := parseSearchParams(r)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results, total := s.animeService.Search(query, parseFilter(r), limit)
+	if results == nil {
+		results = []anime.SearchResult{}
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	respond(w, r, http.StatusOK, map[string]interface{}{
+		"query":   query,
+		"total":   total,
+		"results": results,
+	})
+}
+
+// handleSuggestAnime returns anime names matching a prefix
+func (s *Server) handleSuggestAnime(w http.ResponseWriter, r *http.Request) {
+	prefix, limit, err := parseSuggestParams(r)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	respond(w, r, http.StatusOK, s.animeService.SuggestAnime(prefix, limit))
+}
+
+// handleSuggestCharacters returns character names matching a prefix,
+// optionally restricted to one anime
+func (s *Server) handleSuggestCharacters(w http.ResponseWriter, r *http.Request) {
+	prefix, limit, err := parseSuggestParams(r)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	anime := r.URL.Query().Get("anime")
+	respond(w, r, http.StatusOK, s.animeService.SuggestCharacters(prefix, anime, limit))
+}
+
+// handleAnimeList returns every anime with its quote count
+func (s *Server) handleAnimeList(w http.ResponseWriter, r *http.Request) {
+	respond(w, r, http.StatusOK, s.animeService.ListAnime())
+}
+
+// handleAnime returns an anime and its characters
+func (s *Server) handleAnime(w http.ResponseWriter, r *http.Request) {
+	a, ok := s.animeService.GetAnime(mux.Vars(r)["slug"])
+	if !ok {
+		respondError(w, r, http.StatusNotFound, "anime not found")
+		return
+	}
+	respond(w, r, http.StatusOK, a)
+}
+
+// handleCharacterList returns every character with their quote count
+func (s *Server) handleCharacterList(w http.ResponseWriter, r *http.Request) {
+	respond(w, r, http.StatusOK, s.animeService.ListCharacters())
+}
+
+// handleCharacter returns a character and their quotes
+func (s *Server) handleCharacter(w http.ResponseWriter, r *http.Request) {
+	ch, ok := s.animeService.GetCharacter(mux.Vars(r)["slug"])
+	if !ok {
+		respondError(w, r, http.StatusNotFound, "character not found")
+		return
+	}
+	respond(w, r, http.StatusOK, ch)
 }
 
 // handleHealth returns the health status
@@ -30,22 +164,24 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		"timestamp":   time.Now().UTC().Format(time.RFC3339),
 		"totalQuotes": s.animeService.GetTotalQuotes(),
 		"uptime":      uptime.String(),
-		"version":     "0.0.1",
+		"version":     apiVersion,
 	}
-	respondJSON(w, http.StatusOK, health)
+	respond(w, r, http.StatusOK, health)
 }
 
 // handleStats returns statistics
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 	uptime := time.Since(s.startTime)
 	stats := map[string]interface{}{
-		"totalQuotes": s.animeService.GetTotalQuotes(),
-		"uptime":      uptime.String(),
-		"goVersion":   runtime.Version(),
-		"platform":    fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
-		"theme":       s.cfg.WebUI.Theme,
+		"totalQuotes":     s.animeService.GetTotalQuotes(),
+		"totalAnime":      s.animeService.GetTotalAnime(),
+		"totalCharacters": s.animeService.GetTotalCharacters(),
+		"uptime":          uptime.String(),
+		"goVersion":       runtime.Version(),
+		"platform":        fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		"theme":           s.config().WebUI.Theme,
 	}
-	respondJSON(w, http.StatusOK, stats)
+	respond(w, r, http.StatusOK, stats)
 }
 
 // handleHome renders the homepage with a random quote
@@ -57,81 +193,40 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 		"Quote":       quote,
 		"TotalQuotes": s.animeService.GetTotalQuotes(),
 		"ServerURL":   s.getServerURL(r),
-		"Theme":       s.cfg.WebUI.Theme,
+		"Theme":       s.config().WebUI.Theme,
 	}
 
-	if err := templates.ExecuteTemplate(w, "base.html", data); err != nil {
+	if err := renderPage(w, http.StatusOK, "home", data); err != nil {
 		log.Printf("Error rendering template: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
-// Text format handlers (.txt extension)
-
-// handleRandomQuoteText returns a random quote as plain text
-func (s *Server) handleRandomQuoteText(w http.ResponseWriter, r *http.Request) {
-	quote := s.animeService.GetRandomQuote()
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+// handleQuotePage renders the permalink page for a single quote
+func (s *Server) handleQuotePage(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	quote, ok := s.animeService.GetQuoteByID(id)
 
-	var sb strings.Builder
-	if q, ok := quote.(map[string]interface{}); ok {
-		sb.WriteString(fmt.Sprintf("Quote: %v\n", q["quote"]))
-		sb.WriteString(fmt.Sprintf("Character: %v\n", q["character"]))
-		sb.WriteString(fmt.Sprintf("Anime: %v\n", q["anime"]))
-	} else {
-		sb.WriteString(fmt.Sprintf("%v", quote))
+	status := http.StatusOK
+	title := "Quote"
+	if !ok {
+		status = http.StatusNotFound
+		title = "Quote Not Found"
 	}
-	w.Write([]byte(sb.String()))
-}
-
-// handleAllQuotesText returns all quotes as plain text
-func (s *Server) handleAllQuotesText(w http.ResponseWriter, r *http.Request) {
-	quotes := s.animeService.GetAllQuotes()
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Total Quotes: %d\n", s.animeService.GetTotalQuotes()))
-	sb.WriteString("---\n")
 
-	if quotesSlice, ok := quotes.([]interface{}); ok {
-		for i, q := range quotesSlice {
-			if quote, ok := q.(map[string]interface{}); ok {
-				sb.WriteString(fmt.Sprintf("\n[%d]\n", i+1))
-				sb.WriteString(fmt.Sprintf("Quote: %v\n", quote["quote"]))
-				sb.WriteString(fmt.Sprintf("Character: %v\n", quote["character"]))
-				sb.WriteString(fmt.Sprintf("Anime: %v\n", quote["anime"]))
-			}
-		}
+	data := map[string]interface{}{
+		"Title":     title,
+		"Page":      "quote",
+		"Quote":     quote,
+		"QuoteID":   id,
+		"ServerURL": s.getServerURL(r),
+		"Theme":     s.config().WebUI.Theme,
 	}
-	w.Write([]byte(sb.String()))
-}
-
-// handleHealthText returns health status as plain text
-func (s *Server) handleHealthText(w http.ResponseWriter, r *http.Request) {
-	uptime := time.Since(s.startTime)
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 
-	var sb strings.Builder
-	sb.WriteString("Status: healthy\n")
-	sb.WriteString(fmt.Sprintf("Timestamp: %s\n", time.Now().UTC().Format(time.RFC3339)))
-	sb.WriteString(fmt.Sprintf("Total Quotes: %d\n", s.animeService.GetTotalQuotes()))
-	sb.WriteString(fmt.Sprintf("Uptime: %s\n", uptime.String()))
-	sb.WriteString("Version: 0.0.1\n")
-	w.Write([]byte(sb.String()))
-}
-
-// handleStatsText returns statistics as plain text
-func (s *Server) handleStatsText(w http.ResponseWriter, r *http.Request) {
-	uptime := time.Since(s.startTime)
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Total Quotes: %d\n", s.animeService.GetTotalQuotes()))
-	sb.WriteString(fmt.Sprintf("Uptime: %s\n", uptime.String()))
-	sb.WriteString(fmt.Sprintf("Go Version: %s\n", runtime.Version()))
-	sb.WriteString(fmt.Sprintf("Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH))
-	sb.WriteString(fmt.Sprintf("Theme: %s\n", s.cfg.WebUI.Theme))
-	w.Write([]byte(sb.String()))
+	if err := renderPage(w, status, "quote", data); err != nil {
+		log.Printf("Error rendering template: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
 }
 
 // respondJSON sends a JSON response
@@ -154,7 +249,8 @@ func loggingMiddleware(next http.Handler) http.Handler {
 		start := time.Now()
 		next.ServeHTTP(w, r)
 		log.Printf(
-			"%s %s %s",
+			"%s %s %s %s",
+			clientIP(r),
 			r.Method,
 			r.RequestURI,
 			time.Since(start),
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apimgr/anime/src/anime"
+	"github.com/gorilla/websocket"
+)
+
+// Live stream timing. Heartbeats keep idle proxies from closing the
+// connection between quotes; writes that stall longer than the write
+// timeout end the stream. The heartbeat is a variable so tests can
+// shorten it.
+var streamHeartbeat = 15 * time.Second
+
+const streamWriteTimeout = 10 * time.Second
+
+// streamMessage is one quote pushed to a live stream
+type streamMessage struct {
+	Type  string      `json:"type"`
+	ID    string      `json:"id"`
+	Quote anime.Quote `json:"quote"`
+}
+
+// streamEventID identifies the nth quote of a stream's sequence
+func streamEventID(seed string, n int64) string {
+	return seed + ":" + strconv.FormatInt(n, 10)
+}
+
+// parseStreamEventID splits an event ID into its seed and position
+func parseStreamEventID(id string) (string, int64, error) {
+	i := strings.LastIndex(id, ":")
+	if i > 0 {
+		if n, err := strconv.ParseInt(id[i+1:], 10, 64); err == nil && n >= 0 {
+			return id[:i], n, nil
+		}
+	}
+	return "", 0, fmt.Errorf("invalid event ID %q", id)
+}
+
+// newStreamSeed returns a random seed for a new stream
+func newStreamSeed() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// nextStreamMessage returns the next quote of the stream and advances it
+func (s *Server) nextStreamMessage(p *streamParams) (streamMessage, bool) {
+	quote := s.animeService.SequenceQuote(p.filter, p.seed, p.next)
+	if quote == nil {
+		return streamMessage{}, false
+	}
+	msg := streamMessage{Type: "quote", ID: streamEventID(p.seed, p.next), Quote: *quote}
+	p.next++
+	return msg, true
+}
+
+// handleStream pushes a quote every interval as Server-Sent Events. Each
+// event carries an ID, so a client reconnecting with Last-Event-ID picks up
+// the sequence where it left off.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	p, err := parseStreamParams(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	msg, ok := s.nextStreamMessage(&p)
+	if !ok {
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "no quotes match the given filters"})
+		return
+	}
+
+	// The stream outlives the server's read and write timeouts, so each
+	// write gets its own deadline instead
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(event string) bool {
+		rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprint(w, event); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	// Ask clients to reconnect after one interval if the connection drops
+	if !write(fmt.Sprintf("retry: %d\n\n", p.interval.Milliseconds())) {
+		return
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		data, err := json.Marshal(msg.Quote)
+		if err != nil {
+			log.Printf("Error encoding stream event: %v", err)
+			return
+		}
+		if !write(fmt.Sprintf("id: %s\nevent: quote\ndata: %s\n\n", msg.ID, data)) {
+			return
+		}
+
+		for next := false; !next; {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				if !write(": heartbeat\n\n") {
+					return
+				}
+			case <-ticker.C:
+				next = true
+			}
+		}
+		if msg, ok = s.nextStreamMessage(&p); !ok {
+			// Quotes were reloaded and none match the filter anymore
+			write("event: end\ndata: no quotes match the given filters\n\n")
+			return
+		}
+	}
+}
+
+// handleWebSocket pushes a quote every interval over a WebSocket. Messages
+// are JSON objects with the quote and its event ID; pass the last ID as
+// last_event_id when reconnecting to pick up where the stream left off.
+// Heartbeats are WebSocket pings.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	p, err := parseStreamParams(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	msg, ok := s.nextStreamMessage(&p)
+	if !ok {
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "no quotes match the given filters"})
+		return
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkWebSocketOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an error response
+		return
+	}
+	defer conn.Close()
+
+	// Read in the background so pongs and close frames are handled; the
+	// client is gone once nothing, not even a pong, arrives for two
+	// heartbeats
+	closed := make(chan struct{})
+	conn.SetReadDeadline(time.Now().Add(2 * streamHeartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * streamHeartbeat))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if err := conn.WriteJSON(msg); err != nil {
+			return
+		}
+
+		for next := false; !next; {
+			select {
+			case <-closed:
+				return
+			case <-heartbeat.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+					return
+				}
+			case <-ticker.C:
+				next = true
+			}
+		}
+		if msg, ok = s.nextStreamMessage(&p); !ok {
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, "no quotes match the given filters"),
+				time.Now().Add(streamWriteTimeout))
+			return
+		}
+	}
+}
+
+// checkWebSocketOrigin allows the origins the CORS setting allows. Requests
+// without an Origin header come from non-browser clients and are allowed.
+func (s *Server) checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	corsOrigin := s.config().WebSecurity.CORS
+	if origin == "" || corsOrigin == "" || corsOrigin == "*" {
+		return true
+	}
+	for _, allowed := range strings.Split(corsOrigin, ",") {
+		if strings.TrimSpace(allowed) == origin {
+			return true
+		}
+	}
+	return false
+}
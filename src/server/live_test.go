@@ -0,0 +1,262 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apimgr/anime/src/anime"
+	"github.com/gorilla/websocket"
+)
+
+// openStream starts an SSE stream and returns its response. The stream is
+// closed when the test ends, so servers must be closed by t.Cleanup too.
+func openStream(t *testing.T, url string, header http.Header) *http.Response {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// readEvent reads the next block of an SSE stream, up to a blank line, as
+// its field lines
+func readEvent(t *testing.T, br *bufio.Reader) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+// eventField returns the value of a field in an SSE event
+func eventField(lines []string, field string) string {
+	for _, line := range lines {
+		if v, ok := strings.CutPrefix(line, field+": "); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+func TestStreamResume(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	tests := []struct {
+		name   string
+		query  string
+		header http.Header
+		seed   string // seed and position of the first quote
+		n      int64
+	}{
+		{"new stream", "?seed=abc", nil, "abc", 0},
+		{"Last-Event-ID", "", http.Header{"Last-Event-Id": {"abc:0"}}, "abc", 1},
+		{"last_event_id", "?last_event_id=abc:6", nil, "abc", 7},
+		// The header wins over the parameter, and its seed over seed=
+		{"header first", "?seed=xyz&last_event_id=abc:1", http.Header{"Last-Event-Id": {"abc:4"}}, "abc", 5},
+		{"seed with colons", "?last_event_id=a:b:2", nil, "a:b", 3},
+	}
+	for _, tt := range tests {
+		resp := openStream(t, ts.URL+"/api/v1/stream"+tt.query, tt.header)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("%s: %d %q", tt.name, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		br := bufio.NewReader(resp.Body)
+		if retry := readEvent(t, br); eventField(retry, "retry") != "10000" {
+			t.Errorf("%s: first event %q, want retry: 10000", tt.name, retry)
+		}
+
+		event := readEvent(t, br)
+		if got, want := eventField(event, "id"), streamEventID(tt.seed, tt.n); got != want {
+			t.Errorf("%s: id %q, want %q", tt.name, got, want)
+		}
+		var quote anime.Quote
+		if err := json.Unmarshal([]byte(eventField(event, "data")), &quote); err != nil {
+			t.Fatalf("%s: data %q: %v", tt.name, eventField(event, "data"), err)
+		}
+		if want := s.animeService.SequenceQuote(anime.Filter{}, tt.seed, tt.n); quote != *want {
+			t.Errorf("%s: quote %+v, want %+v", tt.name, quote, *want)
+		}
+	}
+}
+
+func TestStreamHeartbeat(t *testing.T) {
+	heartbeat := streamHeartbeat
+	t.Cleanup(func() { streamHeartbeat = heartbeat })
+	streamHeartbeat = 20 * time.Millisecond
+
+	s := newTestServer(t)
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	// Heartbeats are comments sent while waiting for the next quote
+	resp := openStream(t, ts.URL+"/api/v1/stream?interval=60", nil)
+	br := bufio.NewReader(resp.Body)
+	readEvent(t, br) // retry
+	if event := readEvent(t, br); eventField(event, "event") != "quote" {
+		t.Fatalf("first event %q, want a quote", event)
+	}
+	for i := 0; i < 2; i++ {
+		if event := readEvent(t, br); len(event) != 1 || event[0] != ": heartbeat" {
+			t.Errorf("event %q while waiting, want a heartbeat", event)
+		}
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		target  string
+		status  int
+		errText string
+	}{
+		{"/api/v1/stream?anime=Bleach", http.StatusNotFound, "no quotes match"},
+		{"/api/v1/ws?character=Edward+Elric+Jr", http.StatusNotFound, "no quotes match"},
+		{"/api/v1/stream?interval=0", http.StatusBadRequest, "invalid interval"},
+		{"/api/v1/ws?interval=3601", http.StatusBadRequest, "invalid interval"},
+		{"/api/v1/stream?last_event_id=abc", http.StatusBadRequest, "invalid event ID"},
+		{"/api/v1/stream?last_event_id=abc:-1", http.StatusBadRequest, "invalid event ID"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.errText) {
+			t.Errorf("GET %s: %d %s, want %d mentioning %q", tt.target, w.Code, w.Body.String(), tt.status, tt.errText)
+		}
+	}
+}
+
+func TestStreamLimit(t *testing.T) {
+	s := newTestServer(t)
+	cfg := *s.config()
+	cfg.Server.Limits.MaxStreams = 1
+	s.setConfig(&cfg)
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/v1/stream", nil)
+	first, err := http.DefaultClient.Do(req)
+	if err != nil || first.StatusCode != http.StatusOK {
+		t.Fatalf("first stream: %v %v", first, err)
+	}
+
+	// SSE and WebSocket share the cap
+	for _, path := range []string{"/api/v1/stream", "/api/v1/ws"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") != "30" {
+			t.Errorf("%s over max_streams: %d, Retry-After %q, want 503 and 30", path, resp.StatusCode, resp.Header.Get("Retry-After"))
+		}
+	}
+
+	// Closing the stream frees its slot
+	cancel()
+	first.Body.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp := openStream(t, ts.URL+"/api/v1/stream", nil)
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stream after closing the first: %d", resp.StatusCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebSocket(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/ws"
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?seed=abc&last_event_id=abc:2", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	var msg streamMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := s.animeService.SequenceQuote(anime.Filter{}, "abc", 3)
+	if msg.Type != "quote" || msg.ID != "abc:3" || msg.Quote != *want {
+		t.Errorf("message %+v, want quote abc:3 %+v", msg, *want)
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	s := newTestServer(t)
+	cfg := *s.config()
+	cfg.WebSecurity.CORS = "https://quotes.example.com, https://app.example.com"
+	s.setConfig(&cfg)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/ws"
+
+	tests := []struct {
+		origin string
+		ok     bool
+	}{
+		{"", true},
+		{"https://quotes.example.com", true},
+		{"https://app.example.com", true},
+		{"https://evil.example.com", false},
+		{"http://quotes.example.com", false},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.origin != "" {
+			header.Set("Origin", tt.origin)
+		}
+		conn, resp, err := websocket.DefaultDialer.Dial(wsURL, header)
+		if tt.ok {
+			if err != nil {
+				t.Errorf("origin %q: %v", tt.origin, err)
+				continue
+			}
+			conn.Close()
+			continue
+		}
+		if err == nil {
+			conn.Close()
+			t.Errorf("origin %q: connected, want it refused", tt.origin)
+		} else if resp == nil || resp.StatusCode != http.StatusForbidden {
+			t.Errorf("origin %q: %v, want 403", tt.origin, err)
+		}
+	}
+}
@@ -4,33 +4,14 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/netip"
 	"strings"
 	"time"
 
+	"github.com/apimgr/anime/src/config"
 	"github.com/go-chi/httprate"
 )
 
-// Rate limiting configuration
-const (
-	// Global rate limit (all endpoints)
-	globalRPS   = 100 // 100 requests per second
-	globalBurst = 200
-
-	// API rate limit (stricter for API)
-	apiRPS   = 50
-	apiBurst = 100
-
-	// Request size limits
-	maxBodySize   = 10 << 20 // 10MB
-	maxHeaderSize = 1 << 20  // 1MB
-
-	// Connection limits
-	maxConcurrentRequests = 1000
-
-	// Timeouts
-	maxRequestTimeout = 60 * time.Second
-)
-
 // securityHeadersMiddleware adds security headers to all responses
 func (s *Server) securityHeadersMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -53,23 +34,40 @@ func (s *Server) securityHeadersMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// requestSizeLimitMiddleware limits the size of incoming requests
-func requestSizeLimitMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
-		next.ServeHTTP(w, r)
-	})
+// requestSizeLimitMiddleware limits the size of incoming request bodies
+func requestSizeLimitMiddleware(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
-// throttleMiddleware limits concurrent requests
-func throttleMiddleware(maxConcurrent int) func(http.Handler) http.Handler {
-	semaphore := make(chan struct{}, maxConcurrent)
+// deadlineMiddleware applies the read and write timeouts to each request,
+// so changed values take effect without a restart. The listener's own
+// timeouts, fixed at startup, still bound reading the request headers.
+// Live streams extend the deadlines as they go.
+func deadlineMiddleware(read, write time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rc := http.NewResponseController(w)
+			now := time.Now()
+			rc.SetReadDeadline(now.Add(read))
+			rc.SetWriteDeadline(now.Add(write))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
+// throttleMiddleware limits concurrent requests to the capacity of
+// inFlight, which the gRPC interceptors share
+func throttleMiddleware(inFlight chan struct{}) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			select {
-			case semaphore <- struct{}{}:
-				defer func() { <-semaphore }()
+			case inFlight <- struct{}{}:
+				defer func() { <-inFlight }()
 				next.ServeHTTP(w, r)
 			default:
 				log.Printf("Too many concurrent requests, rejecting: %s %s", r.Method, r.RequestURI)
@@ -79,11 +77,32 @@ func throttleMiddleware(maxConcurrent int) func(http.Handler) http.Handler {
 	}
 }
 
+// streamLimitMiddleware caps the number of open live streams. Streams also
+// count against throttleMiddleware, so the cap (below max_concurrent) keeps
+// them from taking the slots regular requests need.
+func streamLimitMiddleware(maxStreams int) func(http.Handler) http.Handler {
+	semaphore := make(chan struct{}, maxStreams)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+				next.ServeHTTP(w, r)
+			default:
+				log.Printf("Too many open streams, rejecting: %s %s", r.Method, r.RequestURI)
+				w.Header().Set("Retry-After", "30")
+				respondJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "too many open streams, try again later"})
+			}
+		})
+	}
+}
+
 // corsMiddleware handles CORS based on config
 func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Get CORS setting from config (default: *)
-		corsOrigin := s.cfg.WebSecurity.CORS
+		corsOrigin := s.config().WebSecurity.CORS
 		if corsOrigin == "" {
 			corsOrigin = "*"
 		}
@@ -119,41 +138,45 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// globalRateLimitMiddleware applies global rate limiting by IP
-func globalRateLimitMiddleware() func(http.Handler) http.Handler {
-	return httprate.LimitByIP(globalRPS, 1*time.Second)
-}
-
-// apiRateLimitMiddleware applies API-specific rate limiting by IP
-func apiRateLimitMiddleware() func(http.Handler) http.Handler {
-	return httprate.LimitByIP(apiRPS, 1*time.Second)
+// limitState is the rate limit and concurrency state built from
+// server.limits and server.trusted_proxies. The HTTP middleware and the gRPC
+// interceptors share it, so a client's calls over either protocol count
+// against the same budgets.
+type limitState struct {
+	trusted  []netip.Prefix
+	global   *httprate.RateLimiter
+	api      *httprate.RateLimiter
+	inFlight chan struct{}
 }
 
-// getClientIP extracts the client IP address from the request
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header (when behind proxy)
-	xff := r.Header.Get("X-Forwarded-For")
-	if xff != "" {
-		ips := strings.Split(xff, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
+// newLimitState creates fresh limiters; counters start at zero
+func newLimitState(limits config.LimitsConfig, trusted []netip.Prefix) *limitState {
+	return &limitState{
+		trusted:  trusted,
+		global:   newRateLimiter(limits.GlobalRPS),
+		api:      newRateLimiter(limits.APIRPS),
+		inFlight: make(chan struct{}, limits.MaxConcurrent),
 	}
+}
 
-	// Check X-Real-IP header (when behind proxy)
-	xri := r.Header.Get("X-Real-IP")
-	if xri != "" {
-		return strings.TrimSpace(xri)
-	}
+// newRateLimiter creates a limiter allowing rps requests per second. Callers
+// pass the key from rateLimitKey, so HTTP and gRPC count per client IP alike.
+func newRateLimiter(rps int) *httprate.RateLimiter {
+	return httprate.NewRateLimiter(rps, 1*time.Second)
+}
 
-	// Fallback to RemoteAddr
-	ip := r.RemoteAddr
-	if idx := strings.LastIndex(ip, ":"); idx != -1 {
-		ip = ip[:idx]
+// rateLimitMiddleware rejects requests once the client's budget in limiter
+// is spent
+func rateLimitMiddleware(limiter *httprate.RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, _ := rateLimitKey(r)
+			if limiter.RespondOnLimit(w, r, key) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
 	}
-	ip = strings.Trim(ip, "[]")
-
-	return ip
 }
 
 // recoverMiddleware recovers from panics and logs them
@@ -169,28 +192,5 @@ func recoverMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// timeoutMiddleware adds a timeout to requests (not currently used but available)
-func timeoutMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		done := make(chan bool)
-		timer := time.NewTimer(maxRequestTimeout)
-		defer timer.Stop()
-
-		go func() {
-			next.ServeHTTP(w, r)
-			done <- true
-		}()
-
-		select {
-		case <-done:
-			return
-		case <-timer.C:
-			log.Printf("Request timeout: %s %s", r.Method, r.RequestURI)
-			http.Error(w, "Request Timeout", http.StatusRequestTimeout)
-			return
-		}
-	})
-}
-
 // Unused but kept for reference
 var _ = fmt.Sprintf
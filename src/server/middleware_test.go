@@ -0,0 +1,96 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// apiRequest sends an API request from a fixed client address
+func apiRequest(s *Server) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", "/api/v1/random", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestRouterRebuildOnLimitChange(t *testing.T) {
+	s := newTestServer(t)
+	cfg := *s.config()
+	cfg.Server.Limits.APIRPS = 2
+	s.setConfig(&cfg)
+
+	for i := 0; i < 2; i++ {
+		if w := apiRequest(s); w.Code != http.StatusOK {
+			t.Fatalf("request %d: %d", i+1, w.Code)
+		}
+	}
+	if w := apiRequest(s); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over api_rps: %d, want 429", w.Code)
+	}
+
+	// Settings outside the limits keep the router and its counters
+	router := s.router.Load()
+	other := cfg
+	other.Server.FQDN = "quotes.example.com"
+	s.setConfig(&other)
+	if s.router.Load() != router {
+		t.Errorf("router rebuilt for an fqdn change")
+	}
+	if w := apiRequest(s); w.Code != http.StatusTooManyRequests {
+		t.Errorf("counters reset by an fqdn change: %d", w.Code)
+	}
+
+	// A new rate applies at once, with fresh counters
+	raised := other
+	raised.Server.Limits.APIRPS = 3
+	s.setConfig(&raised)
+	if s.router.Load() == router {
+		t.Fatalf("router not rebuilt for an api_rps change")
+	}
+	w := apiRequest(s)
+	if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "3" {
+		t.Errorf("after raising api_rps: %d, X-RateLimit-Limit %q", w.Code, w.Header().Get("X-RateLimit-Limit"))
+	}
+
+	// So do timeouts
+	router = s.router.Load()
+	timeouts := raised
+	timeouts.Server.Limits.ReadTimeout = 30 * time.Second
+	s.setConfig(&timeouts)
+	if s.router.Load() == router {
+		t.Errorf("router not rebuilt for a read_timeout change")
+	}
+}
+
+func TestReadTimeoutAppliesLive(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	cfg := *s.config()
+	cfg.Server.Limits.ReadTimeout = time.Second
+	s.setConfig(&cfg)
+
+	// Send half a body and wait for the server to give up on the rest
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "POST /api/graphql HTTP/1.1\r\nHost: test\r\nContent-Type: application/json\r\nContent-Length: 100\r\n\r\n{\"query\":")
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	start := time.Now()
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("server waited %v for the body, want about the 1s read_timeout", elapsed)
+	}
+}
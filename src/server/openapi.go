@@ -0,0 +1,674 @@
+package server
+
+import (
+	"log"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/apimgr/anime/src/auth"
+)
+
+// apiVersion is the version reported by the health endpoint and the API docs
+const apiVersion = "0.0.1"
+
+// OpenAPI 3.0 document. Only the parts this API uses are modeled; struct
+// field order is kept in the YAML output.
+type openAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       openAPIInfo                `json:"info"`
+	Servers    []openAPIServer            `json:"servers,omitempty"`
+	Tags       []openAPITag               `json:"tags"`
+	Paths      map[string]openAPIPathItem `json:"paths"`
+	Components openAPIComponents          `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Version     string         `json:"version"`
+	License     openAPILicense `json:"license"`
+}
+
+type openAPILicense struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type openAPIPathItem struct {
+	Get  *openAPIOperation `json:"get,omitempty"`
+	Put  *openAPIOperation `json:"put,omitempty"`
+	Post *openAPIOperation `json:"post,omitempty"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description,omitempty"`
+	Tags        []string                   `json:"tags"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Ref         string         `json:"$ref,omitempty"`
+	Name        string         `json:"name,omitempty"`
+	In          string         `json:"in,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema,omitempty"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                    `json:"required"`
+	Content  map[string]openAPIMedia `json:"content"`
+}
+
+type openAPIResponse struct {
+	Ref         string                   `json:"$ref,omitempty"`
+	Description string                   `json:"description,omitempty"`
+	Headers     map[string]openAPIHeader `json:"headers,omitempty"`
+	Content     map[string]openAPIMedia  `json:"content,omitempty"`
+}
+
+type openAPIHeader struct {
+	Ref         string         `json:"$ref,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Schema      *openAPISchema `json:"schema,omitempty"`
+}
+
+type openAPIMedia struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Default              interface{}               `json:"default,omitempty"`
+	Minimum              *int                      `json:"minimum,omitempty"`
+	Maximum              *int                      `json:"maximum,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	Example              interface{}               `json:"example,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema        `json:"schemas"`
+	Parameters      map[string]openAPIParameter      `json:"parameters"`
+	Headers         map[string]openAPIHeader         `json:"headers"`
+	Responses       map[string]openAPIResponse       `json:"responses"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// schemaRef refers to a shared schema by name
+func schemaRef(name string) *openAPISchema {
+	return &openAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// arrayOf describes a list of items
+func arrayOf(items *openAPISchema) *openAPISchema {
+	return &openAPISchema{Type: "array", Items: items}
+}
+
+// stringSchema describes a string
+func stringSchema(description string) *openAPISchema {
+	return &openAPISchema{Type: "string", Description: description}
+}
+
+// intSchema describes an integer
+func intSchema(description string) *openAPISchema {
+	return &openAPISchema{Type: "integer", Description: description}
+}
+
+// intRange describes a bounded integer parameter with a default
+func intRange(def, lo, hi int) *openAPISchema {
+	return &openAPISchema{Type: "integer", Default: def, Minimum: &lo, Maximum: &hi}
+}
+
+// objectSchema describes an object with the given properties
+func objectSchema(required []string, properties map[string]*openAPISchema) *openAPISchema {
+	return &openAPISchema{Type: "object", Required: required, Properties: properties}
+}
+
+// params refers to shared parameters by name
+func params(names ...string) []openAPIParameter {
+	refs := make([]openAPIParameter, len(names))
+	for i, name := range names {
+		refs[i] = openAPIParameter{Ref: "#/components/parameters/" + name}
+	}
+	return refs
+}
+
+// headerRefs refers to shared headers by name
+func headerRefs(names ...string) map[string]openAPIHeader {
+	headers := make(map[string]openAPIHeader, len(names))
+	for _, name := range names {
+		headers[name] = openAPIHeader{Ref: "#/components/headers/" + name}
+	}
+	return headers
+}
+
+// rateLimitHeaders are sent on every response
+var rateLimitHeaders = []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+
+// apiContent lists the media types an /api/v1 response can be negotiated
+// into, taken from responseFormats. quotes adds the formats that only
+// render quotes (fortune); card adds the quote card images.
+func apiContent(schema *openAPISchema, quotes, card bool) map[string]openAPIMedia {
+	content := make(map[string]openAPIMedia)
+	for _, f := range responseFormats {
+		mediaType, _, err := mime.ParseMediaType(f.contentType)
+		if err != nil || (f.image && !card) || (f.name == "fortune" && !quotes) {
+			continue
+		}
+		switch {
+		case f.image:
+			content[mediaType] = openAPIMedia{Schema: &openAPISchema{Type: "string", Format: "binary"}}
+		case f.name == "json" || f.name == "yaml" || f.name == "xml":
+			content[mediaType] = openAPIMedia{Schema: schema}
+		default:
+			if _, ok := content[mediaType]; !ok {
+				content[mediaType] = openAPIMedia{Schema: &openAPISchema{Type: "string"}}
+			}
+		}
+	}
+	return content
+}
+
+// okResponse builds a 200 response with the rate limit headers and any
+// extra headers
+func okResponse(description string, content map[string]openAPIMedia, headers ...string) openAPIResponse {
+	return openAPIResponse{
+		Description: description,
+		Headers:     headerRefs(append(slices.Clone(rateLimitHeaders), headers...)...),
+		Content:     content,
+	}
+}
+
+// mediaContent describes a response with a single media type
+func mediaContent(mediaType string, schema *openAPISchema) map[string]openAPIMedia {
+	return map[string]openAPIMedia{mediaType: {Schema: schema}}
+}
+
+// operation builds an operation that can also fail with the given shared
+// error responses. Every route is rate limited, so 429 is always included.
+func operation(id, summary, tag string, parameters []openAPIParameter, ok openAPIResponse, errors ...string) *openAPIOperation {
+	op := &openAPIOperation{
+		OperationID: id,
+		Summary:     summary,
+		Tags:        []string{tag},
+		Parameters:  parameters,
+		Responses:   map[string]openAPIResponse{"200": ok},
+	}
+	for _, code := range append(errors, "429") {
+		op.Responses[code] = openAPIResponse{Ref: "#/components/responses/" + openAPIErrorResponses[code]}
+	}
+	return op
+}
+
+// openAPIErrorResponses names the shared error response for each status
+var openAPIErrorResponses = map[string]string{
+	"400": "BadRequest",
+	"401": "Unauthorized",
+	"403": "Forbidden",
+	"404": "NotFound",
+	"406": "NotAcceptable",
+	"429": "TooManyRequests",
+	"503": "ServiceUnavailable",
+}
+
+// apiOperation builds an /api/v1 operation. Those accept the format
+// parameter and can answer 406 when the format is not available.
+func apiOperation(id, summary, tag string, parameters []openAPIParameter, ok openAPIResponse, errors ...string) *openAPIOperation {
+	op := operation(id, summary, tag, append(parameters, params("format")...), ok, append(errors, "406")...)
+	op.Description = "Also served with a format extension on the path (e.g. `.csv`), which takes precedence over the Accept header."
+	return op
+}
+
+// adminOperation builds an operation that needs an admin token with the
+// given scope
+func adminOperation(id, summary, scope string, ok openAPIResponse, errors ...string) *openAPIOperation {
+	op := operation(id, summary, "Admin", nil, ok, append(errors, "401", "403")...)
+	op.Description = "Requires a token with the " + scope + " scope."
+	for _, scheme := range []string{"bearerToken", "apiKey", "loginCookie"} {
+		op.Security = append(op.Security, map[string][]string{scheme: {scope}})
+	}
+	return op
+}
+
+// withBody adds a JSON request body to an operation
+func withBody(op *openAPIOperation, schema *openAPISchema) *openAPIOperation {
+	op.RequestBody = &openAPIRequestBody{Required: true, Content: mediaContent("application/json", schema)}
+	return op
+}
+
+// buildOpenAPI describes every route registered in setupRoutes
+func buildOpenAPI() openAPIDocument {
+	filters := params("anime", "character", "exclude_anime")
+	quote := schemaRef("Quote")
+	html := mediaContent("text/html", stringSchema(""))
+
+	paths := map[string]openAPIPathItem{
+		// Quotes
+		"/api/v1/random": {Get: apiOperation("getRandomQuote", "Get a random quote", "Quotes",
+			append(slices.Clone(filters), params("seed", "theme")...),
+			okResponse("A random quote matching the filters", apiContent(quote, true, true)), "404")},
+		"/api/v1/daily": {Get: apiOperation("getDailyQuote", "Get the quote of the day", "Quotes",
+			params("date", "tz", "theme"),
+			okResponse("The quote of the day", apiContent(quote, true, true), "X-Quote-Date"), "400", "404")},
+		"/api/v1/quotes": {Get: apiOperation("listQuotes", "List quotes", "Quotes",
+			append(slices.Clone(filters), params("page", "per_page", "sort", "fields")...),
+			okResponse("Quotes matching the filters. CSV, TSV and NDJSON are streamed.", apiContent(arrayOf(quote), true, false), "X-Total-Count", "Link"), "400", "404")},
+		"/api/v1/quotes/{id}": {Get: apiOperation("getQuote", "Get a quote by ID", "Quotes",
+			params("id", "theme"),
+			okResponse("The quote", apiContent(quote, true, true)), "404")},
+		"/api/v1/search": {Get: apiOperation("searchQuotes", "Full-text quote search", "Quotes",
+			append(params("q", "searchLimit"), filters...),
+			okResponse("Results ranked by BM25", apiContent(schemaRef("SearchResponse"), false, false), "X-Total-Count"), "400")},
+
+		// Catalog
+		"/api/v1/anime": {Get: apiOperation("listAnime", "List anime with quote counts", "Catalog", nil,
+			okResponse("Every anime sorted by name", apiContent(arrayOf(schemaRef("Anime")), false, false)))},
+		"/api/v1/anime/{slug}": {Get: apiOperation("getAnime", "Get an anime and its characters", "Catalog",
+			params("slug"),
+			okResponse("The anime", apiContent(schemaRef("Anime"), false, false)), "404")},
+		"/api/v1/characters": {Get: apiOperation("listCharacters", "List characters with quote counts", "Catalog", nil,
+			okResponse("Every character sorted by name", apiContent(arrayOf(schemaRef("Character")), false, false)))},
+		"/api/v1/characters/{slug}": {Get: apiOperation("getCharacter", "Get a character, their anime and quotes", "Catalog",
+			params("slug"),
+			okResponse("The character", apiContent(schemaRef("Character"), false, false)), "404")},
+		"/api/v1/suggest/anime": {Get: apiOperation("suggestAnime", "Anime name autocomplete", "Catalog",
+			params("prefix", "suggestLimit"),
+			okResponse("Matching names with quote counts", apiContent(arrayOf(schemaRef("NameCount")), false, false)), "400")},
+		"/api/v1/suggest/characters": {Get: apiOperation("suggestCharacters", "Character name autocomplete", "Catalog",
+			params("prefix", "suggestLimit", "suggestAnime"),
+			okResponse("Matching names with quote counts", apiContent(arrayOf(schemaRef("NameCount")), false, false)), "400")},
+
+		// Service
+		"/api/v1/health": {Get: apiOperation("getHealth", "Health check", "Service", nil,
+			okResponse("The service is healthy", apiContent(schemaRef("Health"), false, false)))},
+		"/api/v1/stats": {Get: apiOperation("getStats", "Statistics", "Service", nil,
+			okResponse("Quote counts and server details", apiContent(schemaRef("Stats"), false, false)))},
+		"/api/v1/openapi": {Get: apiOperation("getOpenAPI", "This OpenAPI document (.json or .yaml)", "Service", nil,
+			okResponse("The OpenAPI 3 document", map[string]openAPIMedia{
+				"application/json": {Schema: &openAPISchema{Type: "object"}},
+				"application/yaml": {Schema: &openAPISchema{Type: "object"}},
+			}))},
+		"/healthz": {Get: operation("getHealthz", "Plain-text health check", "Service", nil,
+			okResponse("OK", mediaContent("text/plain", stringSchema(""))))},
+
+		// Streams
+		"/api/v1/stream": {Get: operation("streamQuotes", "Live quotes as Server-Sent Events", "Streams",
+			append(params("interval", "seed", "last_event_id", "Last-Event-ID"), filters...),
+			okResponse("An event stream. Each `quote` event carries the quote as JSON and an ID to resume from.",
+				mediaContent("text/event-stream", stringSchema(""))), "400", "404", "503")},
+		"/api/v1/ws": {Get: operation("streamQuotesWebSocket", "Live quotes over WebSocket", "Streams",
+			append(params("interval", "seed", "last_event_id"), filters...),
+			openAPIResponse{
+				Description: "Not sent: the request must upgrade to a WebSocket (101 Switching Protocols). Messages are StreamMessage objects.",
+				Content:     mediaContent("application/json", schemaRef("StreamMessage")),
+			}, "400", "404", "503")},
+
+		// GraphQL
+		"/api/graphql": {
+			Get: operation("graphqlQuery", "Run a GraphQL query from query parameters", "GraphQL",
+				[]openAPIParameter{
+					{Name: "query", In: "query", Required: true, Description: "GraphQL document", Schema: stringSchema("")},
+					{Name: "variables", In: "query", Description: "JSON object of variable values", Schema: stringSchema("")},
+					{Name: "operationName", In: "query", Schema: stringSchema("")},
+				},
+				okResponse("The GraphQL result; query errors are listed in errors", mediaContent("application/json", schemaRef("GraphQLResponse"))), "400"),
+			Post: func() *openAPIOperation {
+				op := withBody(operation("graphqlQueryPost", "Run a GraphQL query", "GraphQL", nil,
+					okResponse("The GraphQL result; query errors are listed in errors", mediaContent("application/json", schemaRef("GraphQLResponse"))), "400"),
+					schemaRef("GraphQLRequest"))
+				op.RequestBody.Content["application/graphql"] = openAPIMedia{Schema: stringSchema("GraphQL document")}
+				return op
+			}(),
+		},
+
+		// Feeds
+		"/feed.rss": {Get: operation("getFeedRSS", "Quote of the day feed (RSS 2.0)", "Feeds", nil,
+			okResponse("The last 30 daily quotes", mediaContent("application/rss+xml", stringSchema(""))))},
+		"/feed.atom": {Get: operation("getFeedAtom", "Quote of the day feed (Atom)", "Feeds", nil,
+			okResponse("The last 30 daily quotes", mediaContent("application/atom+xml", stringSchema(""))))},
+		"/feed.json": {Get: operation("getFeedJSON", "Quote of the day feed (JSON Feed 1.1)", "Feeds", nil,
+			okResponse("The last 30 daily quotes", mediaContent("application/feed+json", &openAPISchema{Type: "object"})))},
+
+		// Web UI
+		"/": {Get: operation("getHomePage", "Home page with a random quote", "Web UI", nil,
+			okResponse("HTML page", html))},
+		"/quote/{id}": {Get: operation("getQuotePage", "Quote permalink page", "Web UI", params("id"),
+			okResponse("HTML page", html), "404")},
+		"/graphiql": {Get: operation("getGraphiQL", "GraphQL explorer", "Web UI", nil,
+			okResponse("HTML page", html))},
+		"/docs": {Get: operation("getDocs", "Interactive API documentation", "Web UI", nil,
+			okResponse("HTML page", html))},
+		"/static/{path}": {Get: operation("getStaticFile", "Embedded CSS, JavaScript and images", "Web UI",
+			[]openAPIParameter{{Name: "path", In: "path", Required: true, Schema: stringSchema("")}},
+			okResponse("The file", map[string]openAPIMedia{"*/*": {Schema: &openAPISchema{Type: "string", Format: "binary"}}}), "404")},
+
+		// Admin
+		"/admin/login": {
+			Get: operation("getAdminLoginPage", "Admin login page", "Admin",
+				[]openAPIParameter{{Name: "next", In: "query", Description: "Admin page to open after signing in", Schema: stringSchema("")}},
+				okResponse("HTML page", html)),
+			Post: &openAPIOperation{
+				OperationID: "adminLogin",
+				Summary:     "Sign in with a token",
+				Tags:        []string{"Admin"},
+				RequestBody: &openAPIRequestBody{Required: true, Content: mediaContent("application/x-www-form-urlencoded",
+					objectSchema([]string{"token"}, map[string]*openAPISchema{"token": stringSchema(""), "next": stringSchema("")}))},
+				Responses: map[string]openAPIResponse{
+					"303": {Description: "Signed in; the login cookie holds a new session, valid for 12 hours, and the browser is sent to next"},
+					"401": {Description: "Invalid token; the login page is shown again", Content: html},
+					"429": {Description: "More than 5 attempts in a minute; the login page is shown again", Content: html},
+				},
+			},
+		},
+		"/admin/logout": {Post: &openAPIOperation{
+			OperationID: "adminLogout",
+			Summary:     "Sign out",
+			Tags:        []string{"Admin"},
+			Responses: map[string]openAPIResponse{
+				"303": {Description: "The session ends, the login cookie is cleared and the browser is sent to the login page"},
+				"429": {Ref: "#/components/responses/TooManyRequests"},
+			},
+		}},
+		"/admin": {Get: adminOperation("getAdminPage", "Admin dashboard", auth.ScopeAdminRead,
+			okResponse("HTML page; anonymous visitors are redirected to the login page", html))},
+		"/admin/settings": {Get: adminOperation("getAdminSettingsPage", "Settings page", auth.ScopeAdminRead,
+			okResponse("HTML page; anonymous visitors are redirected to the login page", html))},
+		"/api/v1/admin/settings": {
+			Get: adminOperation("getSettings", "List settings with their current and default values", auth.ScopeAdminRead,
+				okResponse("Settings grouped by category", mediaContent("application/json", schemaRef("Settings")))),
+			Put: withBody(adminOperation("updateSettings", "Change settings and save them to server.yml", auth.ScopeAdminWrite,
+				okResponse("The settings were saved; either all changes apply or none do", mediaContent("application/json", schemaRef("SettingsUpdate"))), "400"),
+				schemaRef("SettingValues")),
+		},
+		"/api/v1/admin/settings/export": {Get: adminOperation("exportSettings", "Download every setting's value", auth.ScopeAdminRead,
+			okResponse("A JSON file the import endpoint accepts", mediaContent("application/json", schemaRef("SettingValues"))))},
+		"/api/v1/admin/settings/import": {Post: withBody(adminOperation("importSettings", "Apply exported settings", auth.ScopeAdminWrite,
+			okResponse("The settings were saved", mediaContent("application/json", schemaRef("SettingsUpdate"))), "400"),
+			schemaRef("SettingValues"))},
+		"/api/v1/admin/settings/reset": {Post: adminOperation("resetSettings", "Restore the default settings", auth.ScopeAdminWrite,
+			okResponse("The defaults were saved", mediaContent("application/json", schemaRef("SettingsUpdate"))))},
+
+		// Special files
+		"/robots.txt": {Get: operation("getRobotsTxt", "Robots file (from config)", "Special Files", nil,
+			okResponse("robots.txt", mediaContent("text/plain", stringSchema(""))))},
+		"/security.txt": {Get: operation("getSecurityTxt", "Security contact", "Special Files", nil,
+			okResponse("security.txt", mediaContent("text/plain", stringSchema(""))))},
+		"/.well-known/security.txt": {Get: operation("getWellKnownSecurityTxt", "Security contact (RFC 9116 location)", "Special Files", nil,
+			okResponse("security.txt", mediaContent("text/plain", stringSchema(""))))},
+		"/manifest.json": {Get: operation("getManifest", "PWA manifest", "Special Files", nil,
+			okResponse("Web app manifest", mediaContent("application/manifest+json", &openAPISchema{Type: "object"})))},
+		"/sw.js": {Get: operation("getServiceWorker", "Service worker", "Special Files", nil,
+			okResponse("JavaScript", mediaContent("application/javascript", stringSchema(""))))},
+	}
+
+	return openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title: "Anime Quotes API",
+			Description: "A fast, lightweight API for anime quotes. Read endpoints need no authentication; the admin " +
+				"pages and /api/v1/admin endpoints need an admin token (see the security schemes).\n\n" +
+				"Every /api/v1 endpoint answers in JSON by default. Other formats are picked with the format " +
+				"parameter, a path extension (/api/v1/random.txt) or the Accept header, in that order.",
+			Version: apiVersion,
+			License: openAPILicense{Name: "MIT", URL: "https://github.com/apimgr/anime/blob/main/LICENSE.md"},
+		},
+		Tags: []openAPITag{
+			{Name: "Quotes", Description: "Random, daily and listed quotes"},
+			{Name: "Catalog", Description: "Anime and characters"},
+			{Name: "Streams", Description: "Live quote streams"},
+			{Name: "GraphQL", Description: "GraphQL endpoint over the same quotes"},
+			{Name: "Feeds", Description: "Quote of the day feeds"},
+			{Name: "Service", Description: "Health, statistics and documentation"},
+			{Name: "Web UI", Description: "HTML pages and assets"},
+			{Name: "Special Files", Description: "robots.txt, security.txt and PWA files"},
+			{Name: "Admin", Description: "Settings pages and API. These need a token created with `anime --token create`."},
+		},
+		Paths:      paths,
+		Components: openAPIComponentsSpec(),
+	}
+}
+
+// openAPIComponentsSpec returns the shared schemas, parameters, headers and
+// responses
+func openAPIComponentsSpec() openAPIComponents {
+	nameList := func(description string) *openAPISchema {
+		return &openAPISchema{Type: "array", Items: stringSchema(""), Description: description}
+	}
+	formatNames := formatNames()
+
+	return openAPIComponents{
+		Schemas: map[string]*openAPISchema{
+			"Quote": objectSchema([]string{"id", "anime", "character", "quote"}, map[string]*openAPISchema{
+				"id":        stringSchema("Stable ID derived from the quote content"),
+				"anime":     stringSchema(""),
+				"character": stringSchema(""),
+				"quote":     stringSchema(""),
+			}),
+			"Anime": objectSchema([]string{"slug", "name", "quoteCount"}, map[string]*openAPISchema{
+				"slug":           stringSchema(""),
+				"name":           stringSchema(""),
+				"quoteCount":     intSchema(""),
+				"characterCount": intSchema(""),
+				"characters":     arrayOf(schemaRef("Character")),
+			}),
+			"Character": objectSchema([]string{"slug", "name", "quoteCount"}, map[string]*openAPISchema{
+				"slug":       stringSchema(""),
+				"name":       stringSchema(""),
+				"quoteCount": intSchema(""),
+				"anime":      arrayOf(schemaRef("Anime")),
+				"quotes":     arrayOf(schemaRef("Quote")),
+			}),
+			"NameCount": objectSchema([]string{"name", "count"}, map[string]*openAPISchema{
+				"name":  stringSchema(""),
+				"count": intSchema("Number of quotes"),
+			}),
+			"SearchResponse": objectSchema([]string{"query", "total", "results"}, map[string]*openAPISchema{
+				"query":   stringSchema(""),
+				"total":   intSchema("Matching quotes before the limit was applied"),
+				"results": arrayOf(schemaRef("SearchResult")),
+			}),
+			"SearchResult": objectSchema([]string{"quote", "score", "highlights"}, map[string]*openAPISchema{
+				"quote":      schemaRef("Quote"),
+				"score":      {Type: "number"},
+				"highlights": arrayOf(schemaRef("Highlight")),
+			}),
+			"Highlight": objectSchema([]string{"field", "start", "end"}, map[string]*openAPISchema{
+				"field": {Type: "string", Enum: []string{"quote", "character", "anime"}},
+				"start": intSchema("Rune offset of the first matched character"),
+				"end":   intSchema("Rune offset after the last matched character"),
+			}),
+			"Health": objectSchema([]string{"status", "timestamp", "totalQuotes"}, map[string]*openAPISchema{
+				"status":      {Type: "string", Example: "healthy"},
+				"timestamp":   {Type: "string", Format: "date-time"},
+				"totalQuotes": intSchema(""),
+				"uptime":      stringSchema(""),
+				"version":     stringSchema(""),
+			}),
+			"Stats": objectSchema([]string{"totalQuotes", "totalAnime", "totalCharacters"}, map[string]*openAPISchema{
+				"totalQuotes":     intSchema(""),
+				"totalAnime":      intSchema(""),
+				"totalCharacters": intSchema(""),
+				"uptime":          stringSchema(""),
+				"goVersion":       stringSchema(""),
+				"platform":        stringSchema(""),
+				"theme":           stringSchema(""),
+			}),
+			"StreamMessage": objectSchema([]string{"type", "id", "quote"}, map[string]*openAPISchema{
+				"type":  {Type: "string", Enum: []string{"quote"}},
+				"id":    stringSchema("Event ID to resume from"),
+				"quote": schemaRef("Quote"),
+			}),
+			"GraphQLRequest": objectSchema([]string{"query"}, map[string]*openAPISchema{
+				"query":         stringSchema("GraphQL document"),
+				"variables":     {Type: "object", AdditionalProperties: &openAPISchema{}},
+				"operationName": stringSchema(""),
+			}),
+			"GraphQLResponse": {Type: "object", Properties: map[string]*openAPISchema{
+				"data":   {Type: "object", AdditionalProperties: &openAPISchema{}},
+				"errors": arrayOf(objectSchema([]string{"message"}, map[string]*openAPISchema{"message": stringSchema("")})),
+			}},
+			"Setting": objectSchema([]string{"key", "category", "type", "value", "default", "requiresRestart"}, map[string]*openAPISchema{
+				"key":             {Type: "string", Example: "web-security.cors"},
+				"category":        stringSchema(""),
+				"type":            {Type: "string", Enum: []string{"string", "bool", "list", "enum"}},
+				"description":     stringSchema(""),
+				"options":         arrayOf(stringSchema("")),
+				"requiresRestart": {Type: "boolean", Description: "Changes take effect after a restart"},
+				"value":           stringSchema("Current value; lists are comma-separated"),
+				"default":         stringSchema(""),
+			}),
+			"Settings": objectSchema([]string{"configFile", "categories", "settings"}, map[string]*openAPISchema{
+				"configFile": stringSchema("Path of server.yml"),
+				"categories": arrayOf(objectSchema([]string{"id", "title"}, map[string]*openAPISchema{
+					"id":    stringSchema(""),
+					"title": stringSchema(""),
+				})),
+				"settings": {Type: "object", Description: "Settings by category ID", AdditionalProperties: arrayOf(schemaRef("Setting"))},
+			}),
+			"SettingValues": {Type: "object", Description: "Setting values by key. Lists may be arrays or comma-separated strings.",
+				AdditionalProperties: &openAPISchema{}, Example: map[string]string{"web-ui.theme": "light"}},
+			"SettingsUpdate": objectSchema([]string{"requiresRestart"}, map[string]*openAPISchema{
+				"updatedCount":    intSchema("Settings in the request (update)"),
+				"importedCount":   intSchema("Settings in the request (import)"),
+				"requiresRestart": {Type: "boolean", Description: "A changed setting only takes effect after a restart"},
+			}),
+			"Error": objectSchema([]string{"error"}, map[string]*openAPISchema{
+				"error": stringSchema(""),
+			}),
+			"NotAcceptable": objectSchema([]string{"error", "supported"}, map[string]*openAPISchema{
+				"error":     stringSchema(""),
+				"supported": arrayOf(&openAPISchema{Type: "string", Enum: formatNames}),
+			}),
+		},
+
+		Parameters: map[string]openAPIParameter{
+			"format": {Name: "format", In: "query", Description: "Response format; overrides the extension and Accept header",
+				Schema: &openAPISchema{Type: "string", Enum: formatNames}},
+			"anime":         {Name: "anime", In: "query", Description: "Only quotes from this anime (repeatable, matched leniently)", Schema: nameList("")},
+			"character":     {Name: "character", In: "query", Description: "Only quotes by this character (repeatable, matched leniently)", Schema: nameList("")},
+			"exclude_anime": {Name: "exclude_anime", In: "query", Description: "Skip quotes from this anime, matched exactly ignoring case (repeatable)", Schema: nameList("")},
+			"seed":          {Name: "seed", In: "query", Description: "Makes the pick reproducible: the same seed and filters give the same quote", Schema: stringSchema("")},
+			"theme":         {Name: "theme", In: "query", Description: "Quote card colors for the svg and png formats", Schema: &openAPISchema{Type: "string", Enum: []string{"dark", "light"}}},
+			"date":          {Name: "date", In: "query", Description: "Day to get the quote for (YYYY-MM-DD); defaults to today", Schema: &openAPISchema{Type: "string", Format: "date"}},
+			"tz":            {Name: "tz", In: "query", Description: "IANA time zone that decides which day is today", Schema: &openAPISchema{Type: "string", Default: "UTC", Example: "Asia/Tokyo"}},
+			"page":          {Name: "page", In: "query", Description: "Page number; pagination only applies when page or per_page is set", Schema: &openAPISchema{Type: "integer", Minimum: intPtr(1)}},
+			"per_page":      {Name: "per_page", In: "query", Description: "Quotes per page", Schema: intRange(defaultPerPage, 1, maxPerPage)},
+			"sort": {Name: "sort", In: "query", Description: "Sort field; prefix with - for descending order",
+				Schema: &openAPISchema{Type: "string", Enum: []string{"id", "-id", "anime", "-anime", "character", "-character"}}},
+			"fields": {Name: "fields", In: "query", Description: "Comma-separated fields to return (" + strings.Join(quoteFields, ", ") + ")", Schema: stringSchema("")},
+			"id":     {Name: "id", In: "path", Required: true, Description: "Quote ID", Schema: &openAPISchema{Type: "string", Example: "3f2a9c1e7b04"}},
+			"slug":   {Name: "slug", In: "path", Required: true, Description: "Slug derived from the name", Schema: &openAPISchema{Type: "string", Example: "fullmetal-alchemist"}},
+			"q":      {Name: "q", In: "query", Required: true, Description: "Search words; wrap words in double quotes for an exact phrase", Schema: stringSchema("")},
+			"searchLimit": {Name: "limit", In: "query", Description: "Maximum number of results",
+				Schema: intRange(defaultSearchLimit, 1, maxSearchLimit)},
+			"prefix": {Name: "prefix", In: "query", Required: true, Description: "Start of a word in the name", Schema: stringSchema("")},
+			"suggestLimit": {Name: "limit", In: "query", Description: "Maximum number of names",
+				Schema: intRange(defaultSuggestLimit, 1, maxSuggestLimit)},
+			"suggestAnime": {Name: "anime", In: "query", Description: "Only characters from this anime", Schema: stringSchema("")},
+			"interval": {Name: "interval", In: "query", Description: "Seconds between quotes",
+				Schema: intRange(defaultStreamInterval, minStreamInterval, maxStreamInterval)},
+			"last_event_id": {Name: "last_event_id", In: "query", Description: "ID of the last quote received, to resume the stream after it", Schema: stringSchema("")},
+			"Last-Event-ID": {Name: "Last-Event-ID", In: "header", Description: "Sent by EventSource when it reconnects", Schema: stringSchema("")},
+		},
+
+		Headers: map[string]openAPIHeader{
+			"X-RateLimit-Limit":     {Description: "Requests allowed per second", Schema: intSchema("")},
+			"X-RateLimit-Remaining": {Description: "Requests left in the current window", Schema: intSchema("")},
+			"X-RateLimit-Reset":     {Description: "Unix time when the window resets", Schema: intSchema("")},
+			"X-Total-Count":         {Description: "Number of matching entries before pagination", Schema: intSchema("")},
+			"Link":                  {Description: "first, prev, next and last page URLs (paginated responses only)", Schema: stringSchema("")},
+			"X-Quote-Date":          {Description: "Day the quote of the day belongs to (YYYY-MM-DD)", Schema: &openAPISchema{Type: "string", Format: "date"}},
+			"Retry-After":           {Description: "Seconds to wait before retrying", Schema: intSchema("")},
+		},
+
+		SecuritySchemes: map[string]openAPISecurityScheme{
+			"bearerToken": {Type: "http", Scheme: "bearer", Description: "Admin token in the Authorization header"},
+			"apiKey":      {Type: "apiKey", In: "header", Name: "X-API-Key", Description: "Admin token in the X-API-Key header"},
+			"loginCookie": {Type: "apiKey", In: "cookie", Name: adminCookie, Description: "Login session set by the admin login page"},
+		},
+
+		Responses: map[string]openAPIResponse{
+			"BadRequest": {Description: "Invalid parameters",
+				Content: mediaContent("application/json", schemaRef("Error"))},
+			"Unauthorized": {Description: "No valid admin token was sent",
+				Headers: map[string]openAPIHeader{"WWW-Authenticate": {Schema: stringSchema("")}},
+				Content: mediaContent("application/json", schemaRef("Error"))},
+			"Forbidden": {Description: "The token lacks the required scope, or a cookie-authenticated change came from another site",
+				Content: mediaContent("application/json", schemaRef("Error"))},
+			"NotFound": {Description: "Nothing matches the request",
+				Content: mediaContent("application/json", schemaRef("Error"))},
+			"NotAcceptable": {Description: "The requested format is not supported or not available for this endpoint",
+				Content: mediaContent("application/json", schemaRef("NotAcceptable"))},
+			"TooManyRequests": {Description: "Rate limit exceeded",
+				Headers: headerRefs(rateLimitHeaders...),
+				Content: mediaContent("text/plain", stringSchema(""))},
+			"ServiceUnavailable": {Description: "Too many open streams",
+				Headers: headerRefs("Retry-After"),
+				Content: mediaContent("application/json", schemaRef("Error"))},
+		},
+	}
+}
+
+// intPtr returns a pointer to n, for optional schema bounds
+func intPtr(n int) *int {
+	return &n
+}
+
+// handleOpenAPI serves the OpenAPI document as JSON or YAML
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if f := requestFormat(r); f.name != "json" && f.name != "yaml" {
+		respondJSON(w, http.StatusNotAcceptable, map[string]interface{}{
+			"error":     "the OpenAPI document is available as json or yaml",
+			"supported": []string{"json", "yaml"},
+		})
+		return
+	}
+
+	doc := buildOpenAPI()
+	doc.Servers = []openAPIServer{{URL: s.baseURL(r)}}
+	respond(w, r, http.StatusOK, doc)
+}
+
+// handleDocs renders the interactive API documentation
+func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title": "API Documentation",
+		"Page":  "docs",
+		"Theme": s.config().WebUI.Theme,
+	}
+	if err := renderPage(w, http.StatusOK, "docs", data); err != nil {
+		log.Printf("Error rendering template: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,228 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/apimgr/anime/src/anime"
+	"github.com/apimgr/anime/src/config"
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+)
+
+const testQuotes = `[
+	{"anime": "Naruto", "character": "Naruto Uzumaki", "quote": "I never go back on my word."},
+	{"anime": "Fullmetal Alchemist", "character": "Edward Elric", "quote": "A lesson without pain is meaningless."}
+]`
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	svc, err := anime.NewService([]byte(testQuotes))
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	s, err := NewServer(svc, config.Default(), filepath.Join(t.TempDir(), "server.yml"), "8080", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return s
+}
+
+// routeVariable matches a path variable with a pattern, e.g. {id:[0-9a-f]+}
+var routeVariable = regexp.MustCompile(`\{(\w+):[^}]*\}`)
+
+// registeredRoutes lists the router's routes as "METHOD /path" in OpenAPI
+// path template syntax
+func registeredRoutes(t *testing.T, router *mux.Router) []string {
+	t.Helper()
+	var routes []string
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if route.GetHandler() == nil {
+			// Subrouter prefix
+			return nil
+		}
+		tmpl, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		tmpl = strings.TrimSuffix(tmpl, "{ext:"+extPattern+"}")
+		tmpl = routeVariable.ReplaceAllString(tmpl, "{$1}")
+		if strings.HasSuffix(tmpl, "/") && tmpl != "/" {
+			// Path prefix route
+			tmpl += "{path}"
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = []string{"GET"}
+		}
+		for _, method := range methods {
+			if method == "OPTIONS" {
+				// CORS preflight, not part of the API
+				continue
+			}
+			routes = append(routes, method+" "+tmpl)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walking routes: %v", err)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// documentedRoutes lists the operations of the OpenAPI document as
+// "METHOD /path"
+func documentedRoutes(doc openAPIDocument) []string {
+	var routes []string
+	for path, item := range doc.Paths {
+		if item.Get != nil {
+			routes = append(routes, "GET "+path)
+		}
+		if item.Put != nil {
+			routes = append(routes, "PUT "+path)
+		}
+		if item.Post != nil {
+			routes = append(routes, "POST "+path)
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+func TestOpenAPIMatchesRouter(t *testing.T) {
+	s := newTestServer(t)
+	registered := registeredRoutes(t, s.router.Load())
+	documented := documentedRoutes(buildOpenAPI())
+
+	inSpec := make(map[string]bool)
+	for _, route := range documented {
+		inSpec[route] = true
+	}
+	inRouter := make(map[string]bool)
+	for _, route := range registered {
+		inRouter[route] = true
+		if !inSpec[route] {
+			t.Errorf("route %s is not described in the OpenAPI document", route)
+		}
+	}
+	for _, route := range documented {
+		if !inRouter[route] {
+			t.Errorf("OpenAPI document describes %s, which is not registered", route)
+		}
+	}
+}
+
+func TestOpenAPIPathParameters(t *testing.T) {
+	doc := buildOpenAPI()
+	for path, item := range doc.Paths {
+		for _, op := range []*openAPIOperation{item.Get, item.Put, item.Post} {
+			if op == nil {
+				continue
+			}
+			declared := make(map[string]bool)
+			for _, p := range op.Parameters {
+				if p.Ref != "" {
+					p = doc.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+				}
+				if p.In == "path" {
+					declared[p.Name] = true
+				}
+			}
+			for _, m := range regexp.MustCompile(`\{(\w+)\}`).FindAllStringSubmatch(path, -1) {
+				if !declared[m[1]] {
+					t.Errorf("%s %s: path parameter %q is not declared", op.OperationID, path, m[1])
+				}
+			}
+		}
+	}
+}
+
+func TestOpenAPIReferencesResolve(t *testing.T) {
+	data, err := json.Marshal(buildOpenAPI())
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch v := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				var target interface{} = doc
+				for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+					m, _ := target.(map[string]interface{})
+					target = m[key]
+				}
+				if target == nil {
+					t.Errorf("unresolved reference %s", ref)
+				}
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(doc)
+}
+
+func TestOpenAPIEndpoint(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+	}{
+		{"/api/v1/openapi.json", http.StatusOK, "application/json"},
+		{"/api/v1/openapi.yaml", http.StatusOK, "application/yaml"},
+		{"/api/v1/openapi.csv", http.StatusNotAcceptable, "application/json"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.path, rec.Code, tt.status)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.contentType) {
+			t.Errorf("%s: Content-Type %q, want %q", tt.path, ct, tt.contentType)
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+
+		var doc struct {
+			OpenAPI string                 `json:"openapi" yaml:"openapi"`
+			Servers []map[string]string    `json:"servers" yaml:"servers"`
+			Paths   map[string]interface{} `json:"paths" yaml:"paths"`
+		}
+		if strings.HasSuffix(tt.path, ".yaml") {
+			err := yaml.Unmarshal(rec.Body.Bytes(), &doc)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.path, err)
+			}
+		} else if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if doc.OpenAPI != "3.0.3" || len(doc.Paths) == 0 || len(doc.Servers) != 1 {
+			t.Errorf("%s: unexpected document: openapi %q, %d paths, servers %v", tt.path, doc.OpenAPI, len(doc.Paths), doc.Servers)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/apimgr/anime/src/anime"
+)
+
+// Pagination limits for list endpoints
+const (
+	defaultPerPage = 50
+	maxPerPage     = 500
+)
+
+// quoteFields lists the fields accepted by the fields= projection
+var quoteFields = []string{"id", "anime", "character", "quote"}
+
+// listParams holds the parsed pagination, sorting and projection options
+type listParams struct {
+	paginate bool
+	page     int
+	perPage  int
+	sort     string
+	fields   []string
+}
+
+// parseListParams reads page, per_page, sort and fields from the query string.
+// Pagination only applies when page or per_page is given, so existing clients
+// fetching the full list keep working.
+func parseListParams(r *http.Request) (listParams, error) {
+	q := r.URL.Query()
+	p := listParams{page: 1, perPage: defaultPerPage}
+
+	if v := q.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return p, fmt.Errorf("invalid page %q", v)
+		}
+		p.page = page
+		p.paginate = true
+	}
+	if v := q.Get("per_page"); v != "" {
+		perPage, err := strconv.Atoi(v)
+		if err != nil || perPage < 1 || perPage > maxPerPage {
+			return p, fmt.Errorf("invalid per_page %q (must be 1-%d)", v, maxPerPage)
+		}
+		p.perPage = perPage
+		p.paginate = true
+	}
+
+	p.sort = q.Get("sort")
+
+	if v := q.Get("fields"); v != "" {
+		for _, field := range strings.Split(v, ",") {
+			field = strings.TrimSpace(field)
+			if !slices.Contains(quoteFields, field) {
+				return p, fmt.Errorf("invalid field %q (valid: %s)", field, strings.Join(quoteFields, ", "))
+			}
+			p.fields = append(p.fields, field)
+		}
+	}
+
+	return p, nil
+}
+
+// apply sorts and pages the quotes and returns the window to render
+func (p listParams) apply(quotes []anime.Quote) ([]anime.Quote, error) {
+	if p.sort != "" {
+		if err := anime.SortQuotes(quotes, p.sort); err != nil {
+			return nil, err
+		}
+	}
+	if !p.paginate {
+		return quotes, nil
+	}
+
+	start := (p.page - 1) * p.perPage
+	if start >= len(quotes) {
+		return []anime.Quote{}, nil
+	}
+	end := start + p.perPage
+	if end > len(quotes) {
+		end = len(quotes)
+	}
+	return quotes[start:end], nil
+}
+
+// lastPage returns the number of the last page for the given total
+func (p listParams) lastPage(total int) int {
+	if total == 0 {
+		return 1
+	}
+	return (total + p.perPage - 1) / p.perPage
+}
+
+// setPaginationHeaders sets X-Total-Count and, when paginating, an RFC 8288
+// Link header with first, prev, next and last relations
+func (p listParams) setPaginationHeaders(w http.ResponseWriter, r *http.Request, total int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if !p.paginate {
+		return
+	}
+
+	last := p.lastPage(total)
+	w.Header().Set("X-Page", strconv.Itoa(p.page))
+	w.Header().Set("X-Per-Page", strconv.Itoa(p.perPage))
+	w.Header().Set("X-Total-Pages", strconv.Itoa(last))
+
+	pageURL := func(page int) string {
+		u := url.URL{Path: r.URL.Path}
+		q := r.URL.Query()
+		q.Set("page", strconv.Itoa(page))
+		q.Set("per_page", strconv.Itoa(p.perPage))
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageURL(1))}
+	if p.page > 1 {
+		prev := p.page - 1
+		if prev > last {
+			prev = last
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(prev)))
+	}
+	if p.page < last {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(p.page+1)))
+	}
+	links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageURL(last)))
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// project reduces each quote to the selected fields, or returns the quotes
+// unchanged when no projection was requested
+func (p listParams) project(quotes []anime.Quote) interface{} {
+	if len(p.fields) == 0 {
+		return quotes
+	}
+
+	projected := make([]map[string]string, len(quotes))
+	for i, q := range quotes {
+		m := make(map[string]string, len(p.fields))
+		for _, field := range p.fields {
+			switch field {
+			case "id":
+				m["id"] = q.ID
+			case "anime":
+				m["anime"] = q.Anime
+			case "character":
+				m["character"] = q.Character
+			case "quote":
+				m["quote"] = q.Quote
+			}
+		}
+		projected[i] = m
+	}
+	return projected
+}
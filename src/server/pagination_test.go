@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/apimgr/anime/src/anime"
+	"github.com/apimgr/anime/src/config"
+)
+
+// pagingQuotes holds five quotes, enough for several pages
+const pagingQuotes = `[
+	{"anime": "Naruto", "character": "Naruto Uzumaki", "quote": "I never go back on my word."},
+	{"anime": "Bleach", "character": "Ichigo Kurosaki", "quote": "I'm not fighting because I want to win."},
+	{"anime": "Fullmetal Alchemist", "character": "Edward Elric", "quote": "A lesson without pain is meaningless."},
+	{"anime": "Death Note", "character": "L", "quote": "Being alone is better than being with the wrong person."},
+	{"anime": "Attack on Titan", "character": "Eren Yeager", "quote": "If you win, you live."}
+]`
+
+// newPagingServer returns a test server serving pagingQuotes
+func newPagingServer(t *testing.T) *Server {
+	t.Helper()
+	svc, err := anime.NewService([]byte(pagingQuotes))
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	s, err := NewServer(svc, config.Default(), filepath.Join(t.TempDir(), "server.yml"), "8080", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return s
+}
+
+func TestQuoteListParams(t *testing.T) {
+	s := newPagingServer(t)
+
+	tests := []struct {
+		query   string
+		status  int
+		anime   []string // anime of the returned quotes, in order
+		keys    string   // sorted keys of the first quote
+		errText string
+	}{
+		{"", http.StatusOK, nil, "anime,character,id,quote", ""},
+		{"?sort=anime", http.StatusOK, []string{"Attack on Titan", "Bleach", "Death Note", "Fullmetal Alchemist", "Naruto"}, "", ""},
+		{"?sort=-anime", http.StatusOK, []string{"Naruto", "Fullmetal Alchemist", "Death Note", "Bleach", "Attack on Titan"}, "", ""},
+		{"?sort=anime&page=2&per_page=2", http.StatusOK, []string{"Death Note", "Fullmetal Alchemist"}, "", ""},
+		{"?sort=anime&page=3&per_page=2", http.StatusOK, []string{"Naruto"}, "", ""},
+		{"?sort=anime&page=9&per_page=2", http.StatusOK, []string{}, "", ""},
+		{"?per_page=500", http.StatusOK, nil, "", ""},
+		{"?sort=anime&fields=anime,quote", http.StatusOK, nil, "anime,quote", ""},
+		{"?fields=id", http.StatusOK, nil, "id", ""},
+		{"?page=0", http.StatusBadRequest, nil, "", "invalid page"},
+		{"?page=two", http.StatusBadRequest, nil, "", "invalid page"},
+		{"?per_page=0", http.StatusBadRequest, nil, "", "invalid per_page"},
+		{"?per_page=501", http.StatusBadRequest, nil, "", "invalid per_page"},
+		{"?sort=year", http.StatusBadRequest, nil, "", "invalid sort field"},
+		{"?sort=-year", http.StatusBadRequest, nil, "", "invalid sort field"},
+		{"?fields=id,rating", http.StatusBadRequest, nil, "", "invalid field"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/quotes"+tt.query, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d %s, want %d", tt.query, w.Code, w.Body.String(), tt.status)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			if !strings.Contains(w.Body.String(), tt.errText) {
+				t.Errorf("%s: error %s, want one mentioning %q", tt.query, w.Body.String(), tt.errText)
+			}
+			continue
+		}
+
+		var quotes []map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &quotes); err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if got := w.Header().Get("X-Total-Count"); got != "5" {
+			t.Errorf("%s: X-Total-Count = %q, want 5", tt.query, got)
+		}
+		if tt.anime != nil {
+			got := make([]string, len(quotes))
+			for i, q := range quotes {
+				got[i] = q["anime"]
+			}
+			if strings.Join(got, "|") != strings.Join(tt.anime, "|") {
+				t.Errorf("%s: anime %q, want %q", tt.query, got, tt.anime)
+			}
+		}
+		if tt.keys != "" && len(quotes) > 0 {
+			var keys []string
+			for key := range quotes[0] {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if got := strings.Join(keys, ","); got != tt.keys {
+				t.Errorf("%s: fields %s, want %s", tt.query, got, tt.keys)
+			}
+		}
+	}
+}
+
+func TestPaginationLinks(t *testing.T) {
+	s := newPagingServer(t)
+
+	tests := []struct {
+		query string
+		links map[string]string // rel → page number
+	}{
+		{"?page=1&per_page=2", map[string]string{"first": "1", "next": "2", "last": "3"}},
+		{"?page=2&per_page=2", map[string]string{"first": "1", "prev": "1", "next": "3", "last": "3"}},
+		{"?page=3&per_page=2", map[string]string{"first": "1", "prev": "2", "last": "3"}},
+		{"?page=7&per_page=2", map[string]string{"first": "1", "prev": "3", "last": "3"}},
+		{"?per_page=5", map[string]string{"first": "1", "last": "1"}},
+		{"?page=2&per_page=2&sort=-anime", map[string]string{"first": "1", "prev": "1", "next": "3", "last": "3"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/quotes"+tt.query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: %d", tt.query, w.Code)
+		}
+		want, _ := url.ParseQuery(strings.TrimPrefix(tt.query, "?"))
+
+		got := parseLinkHeader(t, w.Header().Get("Link"))
+		if len(got) != len(tt.links) {
+			t.Errorf("%s: Link rels %v, want %v", tt.query, got, tt.links)
+			continue
+		}
+		for rel, page := range tt.links {
+			link, ok := got[rel]
+			if !ok {
+				t.Errorf("%s: no %s link in %q", tt.query, rel, w.Header().Get("Link"))
+				continue
+			}
+			u, err := url.Parse(link)
+			if err != nil || u.Path != "/api/v1/quotes" {
+				t.Errorf("%s: %s link %q is not a quotes URL", tt.query, rel, link)
+				continue
+			}
+			// Links keep every other parameter and only change the page
+			want.Set("page", page)
+			if got := u.Query(); got.Encode() != want.Encode() {
+				t.Errorf("%s: %s link query %q, want %q", tt.query, rel, got.Encode(), want.Encode())
+			}
+		}
+	}
+}
+
+// parseLinkHeader maps the rels of an RFC 8288 Link header to their URLs
+func parseLinkHeader(t *testing.T, header string) map[string]string {
+	t.Helper()
+	links := make(map[string]string)
+	if header == "" {
+		return links
+	}
+	for _, part := range strings.Split(header, ", ") {
+		target, params, ok := strings.Cut(part, ">; ")
+		rel, found := strings.CutPrefix(params, `rel="`)
+		if !ok || !found || !strings.HasPrefix(target, "<") {
+			t.Fatalf("malformed Link header %q", header)
+		}
+		links[strings.TrimSuffix(rel, `"`)] = strings.TrimPrefix(target, "<")
+	}
+	return links
+}
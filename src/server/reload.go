@@ -0,0 +1,41 @@
+package server
+
+import (
+	"log"
+
+	"github.com/apimgr/anime/src/config"
+)
+
+// ReloadConfig re-reads the config file and makes it the live
+// configuration, logging each changed setting. If the file cannot be read
+// or fails validation, the current configuration stays in place and the
+// error is returned.
+func (s *Server) ReloadConfig() error {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+
+	cfg, err := config.Load(s.configPath)
+	if err != nil {
+		return err
+	}
+
+	old := s.config()
+	changed := config.ChangedSettings(old, cfg)
+	s.setConfig(cfg)
+
+	if len(changed) == 0 {
+		log.Printf("Configuration reloaded from %s, no settings changed", s.configPath)
+		return nil
+	}
+	log.Printf("Configuration reloaded from %s, %d settings changed:", s.configPath, len(changed))
+	for _, setting := range changed {
+		before, _ := old.Get(setting.Key)
+		after, _ := cfg.Get(setting.Key)
+		note := ""
+		if setting.RequiresRestart {
+			note = " (takes effect after a restart)"
+		}
+		log.Printf("  %s: %q -> %q%s", setting.Key, before, after, note)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReloadConfig(t *testing.T) {
+	var logs syncBuffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	s, _ := newAdminServer(t)
+	reload := func(content string) error {
+		t.Helper()
+		if err := os.WriteFile(s.configPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return s.ReloadConfig()
+	}
+
+	// Changed settings are applied and logged, restart-only ones noted
+	router := s.router.Load()
+	if err := reload("server:\n  port: \"9000\"\n  limits:\n    api_rps: 20\n"); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got := s.config().Server.Limits.APIRPS; got != 20 {
+		t.Errorf("api_rps = %d after reload, want 20", got)
+	}
+	if s.router.Load() == router {
+		t.Errorf("router not rebuilt for a new rate limit")
+	}
+	for _, want := range []string{
+		`server.limits.api_rps: "40" -> "20"` + "\n",
+		`server.port: "" -> "9000" (takes effect after a restart)`,
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("log does not contain %q:\n%s", want, logs.String())
+		}
+	}
+
+	// Unchanged limits keep the router and its counters
+	router = s.router.Load()
+	if err := reload("server:\n  port: \"9000\"\n  fqdn: quotes.example.com\n  limits:\n    api_rps: 20\n"); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if s.router.Load() != router {
+		t.Errorf("router rebuilt although the limits did not change")
+	}
+
+	// Files that fail to parse or validate leave the configuration alone
+	live := s.config()
+	for name, content := range map[string]string{
+		"syntax":     "server: [\n",
+		"validation": "server:\n  limits:\n    api_rps: 0\n",
+		"proxies":    "server:\n  trusted_proxies: [\"not an address\"]\n",
+	} {
+		if err := reload(content); err == nil {
+			t.Errorf("%s: reload succeeded", name)
+		}
+		if s.config() != live || s.router.Load() != router {
+			t.Errorf("%s: failed reload replaced the configuration", name)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// extPattern matches the optional format extension on API routes
+const extPattern = `(?:\.[a-z]+)?`
+
+// responseFormat is one way of rendering an API response. Adding a format
+// only needs a new entry in responseFormats; every API endpoint picks it up.
+type responseFormat struct {
+	name        string
+	aliases     []string
+	contentType string
+	mediaTypes  []string
+	encode      func(w io.Writer, data interface{}) error
+
+	// newRowWriter, when set, lets list responses be streamed one entry
+	// at a time instead of being encoded in memory. elem is the type of
+	// the list's entries.
+	newRowWriter func(w io.Writer, elem reflect.Type) rowWriter
+
+	// image formats draw a single quote as a card
+	image bool
+}
+
+// errUnsupportedData is returned by encoders that cannot render a response,
+// e.g. the image formats for anything but a single quote
+var errUnsupportedData = errors.New("format not available for this endpoint")
+
+// responseFormats lists the supported formats in order of preference. The
+// first entry is the default when the client expresses no preference.
+var responseFormats = []*responseFormat{
+	{
+		name:        "json",
+		contentType: "application/json",
+		mediaTypes:  []string{"application/json"},
+		encode:      encodeJSON,
+	},
+	{
+		name:        "txt",
+		aliases:     []string{"text"},
+		contentType: "text/plain; charset=utf-8",
+		mediaTypes:  []string{"text/plain"},
+		encode:      encodeText,
+	},
+	{
+		name:        "csv",
+		contentType: "text/csv; charset=utf-8",
+		mediaTypes:  []string{"text/csv"},
+		encode:      encodeCSV,
+		newRowWriter: func(w io.Writer, elem reflect.Type) rowWriter {
+			return newTableRowWriter(w, elem, ',')
+		},
+	},
+	{
+		name:        "tsv",
+		contentType: "text/tab-separated-values; charset=utf-8",
+		mediaTypes:  []string{"text/tab-separated-values"},
+		encode:      encodeTSV,
+		newRowWriter: func(w io.Writer, elem reflect.Type) rowWriter {
+			return newTableRowWriter(w, elem, '\t')
+		},
+	},
+	{
+		name:         "ndjson",
+		aliases:      []string{"jsonl"},
+		contentType:  "application/x-ndjson",
+		mediaTypes:   []string{"application/x-ndjson", "application/jsonl"},
+		encode:       encodeNDJSON,
+		newRowWriter: newJSONRowWriter,
+	},
+	{
+		name:        "yaml",
+		aliases:     []string{"yml"},
+		contentType: "application/yaml; charset=utf-8",
+		mediaTypes:  []string{"application/yaml", "application/x-yaml", "text/yaml"},
+		encode:      encodeYAML,
+	},
+	{
+		name:        "xml",
+		contentType: "application/xml; charset=utf-8",
+		mediaTypes:  []string{"application/xml", "text/xml"},
+		encode:      encodeXML,
+	},
+	{
+		name:        "md",
+		aliases:     []string{"markdown"},
+		contentType: "text/markdown; charset=utf-8",
+		mediaTypes:  []string{"text/markdown"},
+		encode:      encodeMarkdown,
+	},
+	{
+		name:        "fortune",
+		contentType: "text/plain; charset=utf-8",
+		encode:      encodeFortune,
+	},
+	{
+		name:        "svg",
+		contentType: "image/svg+xml",
+		mediaTypes:  []string{"image/svg+xml"},
+		encode:      encodeSVG,
+		image:       true,
+	},
+	{
+		name:        "png",
+		contentType: "image/png",
+		mediaTypes:  []string{"image/png"},
+		encode:      encodePNG,
+		image:       true,
+	},
+}
+
+// formatNames returns the names of the supported formats
+func formatNames() []string {
+	names := make([]string, len(responseFormats))
+	for i, f := range responseFormats {
+		names[i] = f.name
+	}
+	return names
+}
+
+// formatByName looks up a format by name or alias
+func formatByName(name string) *responseFormat {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, f := range responseFormats {
+		if f.name == name || slices.Contains(f.aliases, name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// negotiateFormat picks the response format for a request. An explicit
+// ?format= wins over a path extension, which wins over the Accept header.
+func negotiateFormat(r *http.Request) (*responseFormat, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		if f := formatByName(name); f != nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf("unsupported format %q", name)
+	}
+
+	if ext := mux.Vars(r)["ext"]; ext != "" {
+		if f := formatByName(strings.TrimPrefix(ext, ".")); f != nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf("unsupported format %q", ext)
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return responseFormats[0], nil
+	}
+	if f := formatFromAccept(accept); f != nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("none of the accepted media types are supported")
+}
+
+// mediaRange is one entry of an Accept header
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept splits an Accept header into media ranges, skipping entries
+// that fail to parse
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// formatFromAccept returns the supported format the Accept header rates
+// highest. Each format takes the quality of its most specific matching
+// range; ties go to the range listed first, then to our own preference.
+func formatFromAccept(accept string) *responseFormat {
+	ranges := parseAccept(accept)
+
+	var best *responseFormat
+	bestQ, bestSpecificity, bestPos := 0.0, -1, len(ranges)
+	for _, f := range responseFormats {
+		q, specificity, pos := 0.0, -1, len(ranges)
+		for i, mr := range ranges {
+			for _, mediaType := range f.mediaTypes {
+				s := matchMediaRange(mr.mediaType, mediaType)
+				if s > specificity {
+					q, specificity, pos = mr.q, s, i
+				}
+			}
+		}
+		if specificity < 0 || q <= 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && (specificity > bestSpecificity || (specificity == bestSpecificity && pos < bestPos))) {
+			best, bestQ, bestSpecificity, bestPos = f, q, specificity, pos
+		}
+	}
+	return best
+}
+
+// matchMediaRange reports how specifically a media range matches a media
+// type: 2 for an exact match, 1 for type/*, 0 for */* and -1 for no match
+func matchMediaRange(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	default:
+		return -1
+	}
+}
+
+// formatContextKey is the context key for the negotiated response format
+type formatContextKey struct{}
+
+// negotiateMiddleware picks the response format for API requests and
+// rejects requests for formats we cannot produce with 406 Not Acceptable
+func negotiateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		f, err := negotiateFormat(r)
+		if err != nil {
+			respondJSON(w, http.StatusNotAcceptable, map[string]interface{}{
+				"error":     err.Error(),
+				"supported": formatNames(),
+			})
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), formatContextKey{}, f)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestFormat returns the format negotiated for the request, falling back
+// to the default for routes outside the negotiating middleware
+func requestFormat(r *http.Request) *responseFormat {
+	if f, ok := r.Context().Value(formatContextKey{}).(*responseFormat); ok {
+		return f
+	}
+	return responseFormats[0]
+}
+
+// respond renders data in the negotiated format. The body is encoded into a
+// buffer first so an encoding error can still become a 500. List responses
+// in row-based formats are streamed instead.
+func respond(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	f := requestFormat(r)
+	if v := reflect.ValueOf(data); f.newRowWriter != nil && v.Kind() == reflect.Slice {
+		streamRows(w, f, status, v)
+		return
+	}
+
+	var buf bytes.Buffer
+	err := f.encode(&buf, data)
+	if errors.Is(err, errUnsupportedData) {
+		respondJSON(w, http.StatusNotAcceptable, map[string]interface{}{
+			"error":     fmt.Sprintf("format %q is not available for this endpoint", f.name),
+			"supported": formatNames(),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error encoding %s response: %v", f.name, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", f.contentType)
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// respondError sends an error message as JSON whatever format was asked
+// for, so clients can always read it the same way
+func respondError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	respondJSON(w, status, map[string]string{"error": message})
+}
+
+// encodeJSON writes data as JSON
+func encodeJSON(w io.Writer, data interface{}) error {
+	return json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestFormatFromAccept(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string // format name, "" for none
+	}{
+		{"application/json", "json"},
+		{"text/csv", "csv"},
+		{"application/x-yaml", "yaml"},
+		{"application/jsonl", "ndjson"},
+		// Quality values
+		{"text/csv;q=0.5, application/xml", "xml"},
+		{"application/xml;q=0.2, text/markdown;q=0.9", "md"},
+		// Equal quality goes to the range listed first
+		{"text/csv, application/xml", "csv"},
+		// A specific range overrides a wildcard, even to exclude a type
+		{"text/*;q=0.3, text/markdown", "md"},
+		{"*/*, application/json;q=0", "txt"},
+		{"text/*", "txt"},
+		{"image/*", "svg"},
+		// Wildcards fall back to our own preference
+		{"*/*", "json"},
+		{"text/html, */*;q=0.1", "json"},
+		// Parameters other than q are ignored
+		{"text/plain; charset=utf-8", "txt"},
+		{"garbage, text/csv", "csv"},
+		{"text/html", ""},
+		{"application/json;q=0", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if f := formatFromAccept(tt.accept); f != nil {
+			got = f.name
+		}
+		if got != tt.want {
+			t.Errorf("formatFromAccept(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		ext    string
+		accept string
+		want   string // format name, "" for an error
+	}{
+		{"default", "/api/v1/random", "", "", "json"},
+		{"accept", "/api/v1/random", "", "text/plain", "txt"},
+		{"extension beats accept", "/api/v1/random.yaml", ".yaml", "text/plain", "yaml"},
+		{"query beats extension", "/api/v1/random.yaml?format=xml", ".yaml", "text/plain", "xml"},
+		{"alias", "/api/v1/random?format=Markdown", "", "", "md"},
+		{"extension alias", "/api/v1/random.yml", ".yml", "", "yaml"},
+		{"unknown query", "/api/v1/random?format=pdf", "", "application/json", ""},
+		{"unknown extension", "/api/v1/random.pdf", ".pdf", "application/json", ""},
+		{"unacceptable", "/api/v1/random", "", "text/html", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.target, nil)
+		if tt.ext != "" {
+			r = mux.SetURLVars(r, map[string]string{"ext": tt.ext})
+		}
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		f, err := negotiateFormat(r)
+		got := ""
+		if err == nil {
+			got = f.name
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q (error %v), want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestNegotiateResponse(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		target      string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"/api/v1/random", "", http.StatusOK, "application/json"},
+		{"/api/v1/random", "text/csv", http.StatusOK, "text/csv; charset=utf-8"},
+		{"/api/v1/random.txt", "application/json", http.StatusOK, "text/plain; charset=utf-8"},
+		{"/api/v1/random.txt?format=yaml", "", http.StatusOK, "application/yaml; charset=utf-8"},
+		{"/api/v1/random", "text/html", http.StatusNotAcceptable, "application/json"},
+		{"/api/v1/random?format=pdf", "", http.StatusNotAcceptable, "application/json"},
+		// Image formats only render single quotes
+		{"/api/v1/stats.png", "", http.StatusNotAcceptable, "application/json"},
+		// Errors are JSON whatever format was asked for
+		{"/api/v1/random.csv?anime=Bleach", "", http.StatusNotFound, "application/json"},
+		{"/api/v1/quotes?per_page=0", "application/yaml", http.StatusBadRequest, "application/json"},
+		{"/api/v1/quotes/999.png", "", http.StatusNotFound, "application/json"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.target, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		if w.Code != tt.status || w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("GET %s (Accept %q): %d %q, want %d %q", tt.target, tt.accept, w.Code, w.Header().Get("Content-Type"), tt.status, tt.contentType)
+		}
+		if got := w.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept" {
+			t.Errorf("GET %s: Vary = %q, want Accept", tt.target, got)
+		}
+		if tt.status == http.StatusNotAcceptable {
+			var body struct {
+				Error     string   `json:"error"`
+				Supported []string `json:"supported"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error == "" || len(body.Supported) != len(responseFormats) {
+				t.Errorf("GET %s: 406 body %q", tt.target, w.Body.String())
+			}
+		} else if tt.status >= 400 {
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error == "" {
+				t.Errorf("GET %s: %d body %q", tt.target, tt.status, w.Body.String())
+			}
+		}
+	}
+}
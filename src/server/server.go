@@ -1,86 +1,234 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/apimgr/anime/src/anime"
+	"github.com/apimgr/anime/src/auth"
 	"github.com/apimgr/anime/src/config"
+	"github.com/go-chi/httprate"
 	"github.com/gorilla/mux"
+	"github.com/graphql-go/graphql"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
+// quoteIDPattern matches quote IDs in route paths (hex hash with an optional
+// "-N" suffix for duplicate quotes)
+const quoteIDPattern = `[0-9a-f]+(?:-[0-9]+)?`
+
+// loginAttempts is how many sign-in attempts a client may make per minute
+const loginAttempts = 5
+
+// slugPattern matches anime and character slugs in route paths
+const slugPattern = `[^/.]+`
+
 // Server represents the HTTP server
 type Server struct {
-	router       *mux.Router
+	router       atomic.Pointer[mux.Router]
 	animeService *anime.Service
-	cfg          *config.Config
+	cfg          atomic.Pointer[config.Config]
+	configPath   string
+	tokens       *auth.Store
+	sessions     *auth.Sessions
+	// loginLimiter slows down token guessing on the login form
+	loginLimiter *httprate.RateLimiter
 	port         string
 	address      string
 	startTime    time.Time
+
+	// settingsMu serializes configuration changes from the admin settings
+	// API and from reloads
+	settingsMu sync.Mutex
+	// limits and trustedProxies are the settings the current router's
+	// middleware was built with
+	limits         config.LimitsConfig
+	trustedProxies []string
+	// limitState is the limiter state of the current router, which the
+	// gRPC interceptors use too
+	limitState atomic.Pointer[limitState]
+
+	// serveMu guards the servers Start runs and Shutdown stops
+	serveMu    sync.Mutex
+	httpServer *http.Server
+	grpcServer *grpc.Server
+	grpcHealth *health.Server
+	grpcShared bool
+	// grpcActive counts gRPC calls in progress, which Shutdown waits for
+	grpcActive atomic.Int64
+
+	graphqlSchema graphql.Schema
 }
 
-// NewServer creates a new HTTP server
-func NewServer(animeService *anime.Service, cfg *config.Config, port, address string) (*Server, error) {
+// NewServer creates a new HTTP server. Settings changed from the admin
+// pages are saved to configPath, and admin tokens are read from the same
+// directory.
+func NewServer(animeService *anime.Service, cfg *config.Config, configPath, port, address string) (*Server, error) {
 	// Initialize templates
 	if err := initTemplates(); err != nil {
 		return nil, fmt.Errorf("failed to initialize templates: %w", err)
 	}
 
 	s := &Server{
-		router:       mux.NewRouter(),
 		animeService: animeService,
-		cfg:          cfg,
+		configPath:   configPath,
+		tokens:       auth.NewStore(auth.Path(filepath.Dir(configPath))),
+		sessions:     auth.NewSessions(auth.SessionLifetime),
 		port:         port,
 		address:      address,
 		startTime:    time.Now(),
 	}
 
-	s.setupRoutes()
+	s.loginLimiter = httprate.NewRateLimiter(loginAttempts, time.Minute,
+		httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
+			log.Printf("Too many admin logins from %s", clientIP(r))
+			s.renderLogin(w, http.StatusTooManyRequests, r.PostFormValue("next"), "Too many sign-in attempts, try again in a minute")
+		}))
+
+	schema, err := s.newGraphQLSchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build GraphQL schema: %w", err)
+	}
+	s.graphqlSchema = schema
+
+	s.setConfig(cfg)
 	return s, nil
 }
 
-// setupRoutes configures all HTTP routes
-func (s *Server) setupRoutes() {
+// config returns the current configuration. The admin settings API and
+// ReloadConfig may replace it at any time, so read it once per request.
+func (s *Server) config() *config.Config {
+	return s.cfg.Load()
+}
+
+// setConfig makes cfg the live configuration. The limiting and client IP
+// middleware is built from server.limits and server.trusted_proxies, so
+// when those change the router is rebuilt and swapped in; rate limit and
+// connection counters start over.
+func (s *Server) setConfig(cfg *config.Config) {
+	s.cfg.Store(cfg)
+	if s.router.Load() != nil && cfg.Server.Limits == s.limits && slices.Equal(cfg.Server.TrustedProxies, s.trustedProxies) {
+		return
+	}
+	if s.router.Load() != nil {
+		log.Printf("Limits or trusted proxies changed, rebuilding the middleware chain")
+	}
+	// Validate has already checked the list
+	trusted, _ := cfg.Server.TrustedProxyPrefixes()
+	s.limits = cfg.Server.Limits
+	s.trustedProxies = slices.Clone(cfg.Server.TrustedProxies)
+	state := newLimitState(cfg.Server.Limits, trusted)
+	s.limitState.Store(state)
+	s.router.Store(s.newRouter(cfg.Server.Limits, state))
+}
+
+// ServeHTTP serves a request with the current router
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.Load().ServeHTTP(w, r)
+}
+
+// newRouter configures all HTTP routes with middleware enforcing limits.
+// Client addresses are resolved through the trusted proxies in state.
+func (s *Server) newRouter(limits config.LimitsConfig, state *limitState) *mux.Router {
+	router := mux.NewRouter()
+	apiRateLimit := rateLimitMiddleware(state.api)
+
 	// Apply global middleware (in order of execution)
-	s.router.Use(recoverMiddleware)             // Panic recovery
-	s.router.Use(s.securityHeadersMiddleware)   // Security headers
-	s.router.Use(s.corsMiddleware)              // CORS
-	s.router.Use(requestSizeLimitMiddleware)    // Request size limits
-	s.router.Use(throttleMiddleware(maxConcurrentRequests))
-	s.router.Use(globalRateLimitMiddleware())   // Global rate limiting
-	s.router.Use(loggingMiddleware)             // Request logging
+	router.Use(recoverMiddleware)                                           // Panic recovery
+	router.Use(deadlineMiddleware(limits.ReadTimeout, limits.WriteTimeout)) // Read and write timeouts
+	router.Use(clientIPMiddleware(state.trusted))                           // Client IP resolution
+	router.Use(s.securityHeadersMiddleware)                                 // Security headers
+	router.Use(s.corsMiddleware)                                            // CORS
+	router.Use(requestSizeLimitMiddleware(int64(limits.MaxBodyMB) << 20))   // Request size limits
+	router.Use(throttleMiddleware(state.inFlight))                          // Concurrent requests
+	router.Use(rateLimitMiddleware(state.global))                           // Global rate limiting
+	router.Use(loggingMiddleware)                                           // Request logging
 
 	// Static files (CSS, JS, images)
-	s.router.PathPrefix("/static/").Handler(http.StripPrefix("/", s.serveStatic()))
+	router.PathPrefix("/static/").Handler(http.StripPrefix("/", s.serveStatic()))
 
 	// Special files (PWA, robots, security)
-	s.router.HandleFunc("/robots.txt", s.handleRobotsTxt).Methods("GET")
-	s.router.HandleFunc("/security.txt", s.handleSecurityTxt).Methods("GET")
-	s.router.HandleFunc("/.well-known/security.txt", s.handleSecurityTxt).Methods("GET")
-	s.router.HandleFunc("/manifest.json", s.handleManifest).Methods("GET")
-	s.router.HandleFunc("/sw.js", s.handleServiceWorker).Methods("GET")
+	router.HandleFunc("/robots.txt", s.handleRobotsTxt).Methods("GET")
+	router.HandleFunc("/security.txt", s.handleSecurityTxt).Methods("GET")
+	router.HandleFunc("/.well-known/security.txt", s.handleSecurityTxt).Methods("GET")
+	router.HandleFunc("/manifest.json", s.handleManifest).Methods("GET")
+	router.HandleFunc("/sw.js", s.handleServiceWorker).Methods("GET")
+
+	// Quote of the day feeds
+	router.HandleFunc("/feed.rss", s.handleFeedRSS).Methods("GET")
+	router.HandleFunc("/feed.atom", s.handleFeedAtom).Methods("GET")
+	router.HandleFunc("/feed.json", s.handleFeedJSON).Methods("GET")
 
 	// Web UI routes
-	s.router.HandleFunc("/", s.handleHome).Methods("GET")
-	s.router.HandleFunc("/healthz", s.handleHealthz).Methods("GET")
-
-	// API v1 routes (public - NO AUTH per BASE.md) with API-specific rate limiting
-	api := s.router.PathPrefix("/api/v1").Subrouter()
-	api.Use(apiRateLimitMiddleware())
-	api.HandleFunc("/random", s.handleRandomQuote).Methods("GET")
-	api.HandleFunc("/quotes", s.handleAllQuotes).Methods("GET")
-	api.HandleFunc("/health", s.handleHealth).Methods("GET")
-	api.HandleFunc("/stats", s.handleStats).Methods("GET")
-
-	// Text format endpoints (.txt extension)
-	api.HandleFunc("/random.txt", s.handleRandomQuoteText).Methods("GET")
-	api.HandleFunc("/quotes.txt", s.handleAllQuotesText).Methods("GET")
-	api.HandleFunc("/health.txt", s.handleHealthText).Methods("GET")
-	api.HandleFunc("/stats.txt", s.handleStatsText).Methods("GET")
+	router.HandleFunc("/", s.handleHome).Methods("GET")
+	router.HandleFunc("/healthz", s.handleHealthz).Methods("GET")
+	router.HandleFunc("/quote/{id:"+quoteIDPattern+"}", s.handleQuotePage).Methods("GET")
+	router.HandleFunc("/graphiql", s.handleExplorer).Methods("GET")
+	router.HandleFunc("/docs", s.handleDocs).Methods("GET")
+
+	// Admin pages and settings API, which need a token (see requireAdmin)
+	router.HandleFunc("/admin/login", s.handleLoginPage).Methods("GET")
+	router.Handle("/admin/login", rateLimitMiddleware(s.loginLimiter)(http.HandlerFunc(s.handleLogin))).Methods("POST")
+	router.HandleFunc("/admin/logout", s.handleLogout).Methods("POST")
+	adminPage := s.requireAdmin(true)
+	router.Handle("/admin", adminPage(http.HandlerFunc(s.handleAdmin))).Methods("GET")
+	router.Handle("/admin/settings", adminPage(http.HandlerFunc(s.handleAdminSettings))).Methods("GET")
+	admin := router.PathPrefix("/api/v1/admin").Subrouter()
+	admin.Use(s.requireAdmin(false))
+	admin.HandleFunc("/settings", s.handleGetSettings).Methods("GET")
+	admin.HandleFunc("/settings", s.handleUpdateSettings).Methods("PUT")
+	admin.HandleFunc("/settings/export", s.handleExportSettings).Methods("GET")
+	admin.HandleFunc("/settings/import", s.handleImportSettings).Methods("POST")
+	admin.HandleFunc("/settings/reset", s.handleResetSettings).Methods("POST")
+
+	// GraphQL over the same quote service, rate limited like the REST API
+	router.Handle("/api/graphql", apiRateLimit(http.HandlerFunc(s.handleGraphQL))).Methods("GET", "POST", "OPTIONS")
+
+	// Live quote streams. These skip format negotiation, since they always
+	// speak SSE or WebSocket, and share one connection cap.
+	streams := router.PathPrefix("/api/v1").Subrouter()
+	streams.Use(apiRateLimit)
+	streams.Use(streamLimitMiddleware(limits.MaxStreams))
+	streams.HandleFunc("/stream", s.handleStream).Methods("GET")
+	streams.HandleFunc("/ws", s.handleWebSocket).Methods("GET")
+
+	// API v1 routes (public - NO AUTH per BASE.md) with API-specific rate limiting.
+	// Every route also answers with a format extension, e.g. /random.txt.
+	api := router.PathPrefix("/api/v1").Subrouter()
+	api.Use(apiRateLimit)
+	api.Use(negotiateMiddleware)
+	apiRoute(api, "/random", s.handleRandomQuote)
+	apiRoute(api, "/daily", s.handleDaily)
+	apiRoute(api, "/quotes", s.handleAllQuotes)
+	apiRoute(api, "/quotes/{id:"+quoteIDPattern+"}", s.handleQuote)
+	apiRoute(api, "/anime", s.handleAnimeList)
+	apiRoute(api, "/anime/{slug:"+slugPattern+"}", s.handleAnime)
+	apiRoute(api, "/characters", s.handleCharacterList)
+	apiRoute(api, "/characters/{slug:"+slugPattern+"}", s.handleCharacter)
+	apiRoute(api, "/search", s.handleSearch)
+	apiRoute(api, "/suggest/anime", s.handleSuggestAnime)
+	apiRoute(api, "/suggest/characters", s.handleSuggestCharacters)
+	apiRoute(api, "/health", s.handleHealth)
+	apiRoute(api, "/stats", s.handleStats)
+	apiRoute(api, "/openapi", s.handleOpenAPI)
+	return router
+}
+
+// apiRoute registers a GET API route with an optional format extension
+func apiRoute(api *mux.Router, path string, handler http.HandlerFunc) {
+	api.HandleFunc(path+"{ext:"+extPattern+"}", handler).Methods("GET")
 }
 
 // getServerURL returns the server URL for display
@@ -100,13 +248,11 @@ func (s *Server) getServerURL(r *http.Request) string {
 
 // Start starts the HTTP server
 func (s *Server) Start() error {
+	cfg := s.config()
+	limits := cfg.Server.Limits
+
 	// Build listen address
-	var addr string
-	if s.address == "::" {
-		addr = fmt.Sprintf(":%s", s.port)
-	} else {
-		addr = fmt.Sprintf("%s:%s", s.address, s.port)
-	}
+	addr := s.listenAddr(s.port)
 
 	// Format display URL with IPv6 brackets if needed
 	displayURL := addr
@@ -119,29 +265,64 @@ func (s *Server) Start() error {
 	log.Printf("Starting Anime Quotes API server on %s", addr)
 	log.Printf("Total quotes loaded: %d", s.animeService.GetTotalQuotes())
 	log.Printf("")
-	log.Printf("Security Configuration:")
-	log.Printf("  Global Rate Limit:  %d req/s (burst: %d)", globalRPS, globalBurst)
-	log.Printf("  API Rate Limit:     %d req/s (burst: %d)", apiRPS, apiBurst)
-	log.Printf("  Max Request Size:   %d MB", maxBodySize>>20)
-	log.Printf("  Max Header Size:    %d MB", maxHeaderSize>>20)
-	log.Printf("  Max Concurrent:     %d requests", maxConcurrentRequests)
-	log.Printf("  Request Timeout:    %v", maxRequestTimeout)
+	log.Printf("Security Configuration (server.limits):")
+	log.Printf("  Global Rate Limit:  %d req/s per IP", limits.GlobalRPS)
+	log.Printf("  API Rate Limit:     %d req/s per IP", limits.APIRPS)
+	log.Printf("  Max Request Size:   %d MB", limits.MaxBodyMB)
+	log.Printf("  Max Header Size:    %d KB", limits.MaxHeaderKB)
+	log.Printf("  Max Concurrent:     %d requests", limits.MaxConcurrent)
+	log.Printf("  Max Live Streams:   %d connections", limits.MaxStreams)
+	log.Printf("  Timeouts:           read %v, write %v, idle %v", limits.ReadTimeout, limits.WriteTimeout, limits.IdleTimeout)
+	if proxies := cfg.Server.TrustedProxies; len(proxies) > 0 {
+		log.Printf("  Trusted Proxies:    %s", strings.Join(proxies, ", "))
+	} else {
+		log.Printf("  Trusted Proxies:    none (clients are identified by connection address)")
+	}
 	log.Printf("")
 	log.Printf("Web UI:")
 	log.Printf("  GET /                    - Homepage with random quote")
 	log.Printf("  GET /healthz             - Health check")
+	log.Printf("  GET /quote/{id}          - Quote permalink page")
+	log.Printf("  GET /graphiql            - GraphQL explorer")
+	log.Printf("  GET /docs                - Interactive API documentation")
+	log.Printf("  GET /admin               - Admin dashboard and settings (token required)")
 	log.Printf("")
 	log.Printf("API Endpoints:")
-	log.Printf("  GET /api/v1/random       - Get a random quote")
+	log.Printf("  GET /api/v1/random       - Get a random quote (?seed= for a fixed pick)")
+	log.Printf("  GET /api/v1/daily        - Quote of the day (?date=, ?tz=)")
 	log.Printf("  GET /api/v1/quotes       - Get all quotes")
+	log.Printf("  GET /api/v1/quotes/{id}  - Get a quote by ID")
+	log.Printf("  GET /api/v1/anime        - List anime with quote counts")
+	log.Printf("  GET /api/v1/anime/{slug} - Anime details and characters")
+	log.Printf("  GET /api/v1/characters   - List characters with quote counts")
+	log.Printf("  GET /api/v1/characters/{slug} - Character and their quotes")
+	log.Printf("  GET /api/v1/search?q=    - Full-text quote search")
+	log.Printf("  GET /api/v1/suggest/anime?prefix=      - Anime name autocomplete")
+	log.Printf("  GET /api/v1/suggest/characters?prefix= - Character name autocomplete")
 	log.Printf("  GET /api/v1/health       - Health check")
 	log.Printf("  GET /api/v1/stats        - Statistics")
+	log.Printf("  GET /api/v1/openapi.json - OpenAPI 3 document (also .yaml)")
+	log.Printf("  GET /api/v1/stream       - Live quotes as Server-Sent Events (?interval=)")
+	log.Printf("  GET /api/v1/ws           - Live quotes over WebSocket (?interval=)")
+	log.Printf("")
+	log.Printf("GraphQL:")
+	log.Printf("  POST /api/graphql        - quote, quotes, random, anime, characters, stats")
 	log.Printf("")
-	log.Printf("Text Format (add .txt extension):")
-	log.Printf("  GET /api/v1/random.txt   - Random quote as text")
-	log.Printf("  GET /api/v1/quotes.txt   - All quotes as text")
-	log.Printf("  GET /api/v1/health.txt   - Health as text")
-	log.Printf("  GET /api/v1/stats.txt    - Statistics as text")
+	if grpcCfg := cfg.Server.GRPC; grpcCfg.Enabled {
+		if grpcCfg.Port != "" {
+			log.Printf("gRPC (anime.v1.AnimeQuotes, grpc.health.v1.Health) on %s", s.listenAddr(grpcCfg.Port))
+		} else {
+			log.Printf("gRPC (anime.v1.AnimeQuotes, grpc.health.v1.Health) on the HTTP port")
+		}
+		log.Printf("  GetQuote, Random, Search, ListQuotes (server streaming)")
+		log.Printf("")
+	}
+	log.Printf("Response Formats (Accept header, extension or ?format=):")
+	log.Printf("  %s", strings.Join(formatNames(), ", "))
+	log.Printf("  e.g. /api/v1/random.txt, /api/v1/quotes?format=csv")
+	log.Printf("  GET /api/v1/quotes.csv   - Streaming export (also .tsv, .ndjson)")
+	log.Printf("  GET /api/v1/random.svg   - Quote card image (also .png, /quotes/{id}.svg)")
+	log.Printf("  GET /api/v1/quotes.fortune - fortune(6) file")
 	log.Printf("")
 	log.Printf("Special Files:")
 	log.Printf("  GET /robots.txt          - Robots file")
@@ -149,32 +330,138 @@ func (s *Server) Start() error {
 	log.Printf("  GET /manifest.json       - PWA manifest")
 	log.Printf("  GET /sw.js               - Service worker")
 	log.Printf("")
+	log.Printf("Feeds (quote of the day, last %d days):", feedDays)
+	log.Printf("  GET /feed.rss            - RSS 2.0")
+	log.Printf("  GET /feed.atom           - Atom")
+	log.Printf("  GET /feed.json           - JSON Feed")
+	log.Printf("")
 	log.Printf("Access the web UI at: http://%s", displayURL)
 
+	// gRPC shares the HTTP listener unless it has its own port
+	var handler http.Handler = s
+	var grpcServer *grpc.Server
+	var grpcHealth *health.Server
+	h2s := &http2.Server{}
+	errChan := make(chan error, 2)
+	if grpcCfg := cfg.Server.GRPC; grpcCfg.Enabled {
+		grpcServer, grpcHealth = s.newGRPCServer(limits.MaxBodyMB<<20, grpcCfg.Reflection)
+		if grpcCfg.Port != "" {
+			go func() {
+				if err := serveGRPC(grpcServer, s.listenAddr(grpcCfg.Port)); err != nil {
+					errChan <- fmt.Errorf("gRPC server: %w", err)
+				}
+			}()
+		} else {
+			handler = withGRPC(grpcServer, s, h2s)
+		}
+	}
+
 	// Create HTTP server with security timeouts
 	server := &http.Server{
 		Addr:           addr,
-		Handler:        s.router,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		IdleTimeout:    120 * time.Second,
-		MaxHeaderBytes: maxHeaderSize,
+		Handler:        handler,
+		ReadTimeout:    limits.ReadTimeout,
+		WriteTimeout:   limits.WriteTimeout,
+		IdleTimeout:    limits.IdleTimeout,
+		MaxHeaderBytes: limits.MaxHeaderKB << 10,
+	}
+	// Lets Shutdown send GOAWAY on h2c connections
+	if err := http2.ConfigureServer(server, h2s); err != nil {
+		return err
+	}
+
+	s.serveMu.Lock()
+	s.httpServer, s.grpcServer, s.grpcHealth = server, grpcServer, grpcHealth
+	s.grpcShared = grpcServer != nil && cfg.Server.GRPC.Port == ""
+	s.serveMu.Unlock()
+
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			errChan <- err
+		} else {
+			errChan <- nil
+		}
+	}()
+	return <-errChan
+}
+
+// Shutdown stops the servers started by Start. The gRPC health service
+// reports NOT_SERVING first, so probes and load balancers stop sending
+// calls. Requests and calls in progress are then given until ctx is done
+// to finish before their connections are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.serveMu.Lock()
+	httpServer, grpcServer, grpcHealth, grpcShared := s.httpServer, s.grpcServer, s.grpcHealth, s.grpcShared
+	s.serveMu.Unlock()
+
+	if grpcHealth != nil {
+		grpcHealth.Shutdown()
+	}
+	var err error
+	if httpServer != nil {
+		err = httpServer.Shutdown(ctx)
+	}
+	if grpcServer == nil {
+		return err
 	}
 
-	return server.ListenAndServe()
+	// GracefulStop cannot drain calls served over the HTTP port, so wait
+	// for those here; h2c connections were sent GOAWAY above
+	stopped := make(chan struct{})
+	go func() {
+		if grpcShared {
+			s.waitGRPCCalls(ctx)
+			grpcServer.Stop()
+		} else {
+			grpcServer.GracefulStop()
+		}
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+		<-stopped
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	return err
+}
+
+// waitGRPCCalls waits until no gRPC call is in progress or ctx is done
+func (s *Server) waitGRPCCalls(ctx context.Context) {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for s.grpcActive.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// listenAddr returns the address to listen on for a port
+func (s *Server) listenAddr(port string) string {
+	if s.address == "::" {
+		return fmt.Sprintf(":%s", port)
+	}
+	return fmt.Sprintf("%s:%s", s.address, port)
 }
 
 // handleRobotsTxt generates robots.txt from config
 func (s *Server) handleRobotsTxt(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 
+	robots := s.config().WebRobots
 	var sb strings.Builder
 	sb.WriteString("User-agent: *\n")
 
-	for _, path := range s.cfg.WebRobots.Allow {
+	for _, path := range robots.Allow {
 		sb.WriteString(fmt.Sprintf("Allow: %s\n", path))
 	}
-	for _, path := range s.cfg.WebRobots.Deny {
+	for _, path := range robots.Deny {
 		sb.WriteString(fmt.Sprintf("Disallow: %s\n", path))
 	}
 
@@ -185,7 +472,7 @@ func (s *Server) handleRobotsTxt(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleSecurityTxt(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 
-	admin := s.cfg.WebSecurity.Admin
+	admin := s.config().WebSecurity.Admin
 	if admin == "" {
 		admin = "security@apimgr.us"
 	}
@@ -203,7 +490,7 @@ Canonical: https://anime.apimgr.us/.well-known/security.txt
 func (s *Server) handleManifest(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/manifest+json")
 
-	theme := s.cfg.WebUI.Theme
+	theme := s.config().WebUI.Theme
 	bgColor := "#1a1a1a"
 	if theme == "light" {
 		bgColor = "#ffffff"
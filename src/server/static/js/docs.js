@@ -0,0 +1,206 @@
+/**
+ * Anime Quotes API - API Documentation
+ * Lists the endpoints of the OpenAPI document and runs GET requests
+ */
+
+const OPENAPI_URL = '/api/v1/openapi.json';
+
+/**
+ * Resolve a local $ref such as #/components/parameters/page
+ * @param {object} spec - The OpenAPI document
+ * @param {object} value - An object that may be a reference
+ */
+function resolveRef(spec, value) {
+  if (!value || !value.$ref) return value;
+  return value.$ref
+    .replace(/^#\//, '')
+    .split('/')
+    .reduce((node, key) => node[key], spec);
+}
+
+/**
+ * Describe a schema in a few characters, e.g. Quote[] or integer
+ * @param {object} schema - The schema
+ */
+function describeSchema(schema) {
+  if (!schema) return '';
+  if (schema.$ref) return schema.$ref.split('/').pop();
+  if (schema.type === 'array') return describeSchema(schema.items) + '[]';
+  if (schema.enum) return schema.enum.join(' | ');
+  return schema.type || 'any';
+}
+
+/**
+ * Build the parameter table and try-it form of an operation
+ * @param {object} spec - The OpenAPI document
+ * @param {string} path - The path template
+ * @param {object} operation - The operation
+ * @param {boolean} tryIt - Whether to add the try-it form
+ */
+function renderOperationBody(spec, path, operation, tryIt) {
+  const body = document.createElement('div');
+  body.className = 'docs-operation-body';
+
+  if (operation.description) {
+    const description = document.createElement('p');
+    description.textContent = operation.description;
+    body.appendChild(description);
+  }
+
+  const parameters = (operation.parameters || []).map((p) => resolveRef(spec, p));
+  const inputs = [];
+  if (parameters.length > 0) {
+    const table = document.createElement('table');
+    table.className = 'docs-parameters';
+    table.innerHTML = '<thead><tr><th>Parameter</th><th>In</th><th>Type</th><th>Description</th>' +
+      (tryIt ? '<th>Value</th>' : '') + '</tr></thead>';
+    const tbody = document.createElement('tbody');
+    for (const param of parameters) {
+      const row = document.createElement('tr');
+      for (const text of [param.name + (param.required ? ' *' : ''), param.in, describeSchema(param.schema), param.description || '']) {
+        const cell = document.createElement('td');
+        cell.textContent = text;
+        row.appendChild(cell);
+      }
+      if (tryIt) {
+        const cell = document.createElement('td');
+        if (param.in === 'path' || param.in === 'query') {
+          const input = document.createElement('input');
+          input.className = 'form-input';
+          input.placeholder = param.schema && param.schema.example !== undefined ? String(param.schema.example) : '';
+          inputs.push({ param, input });
+          cell.appendChild(input);
+        }
+        row.appendChild(cell);
+      }
+      tbody.appendChild(row);
+    }
+    table.appendChild(tbody);
+    body.appendChild(table);
+  }
+
+  const responses = document.createElement('ul');
+  responses.className = 'docs-responses';
+  for (const [status, value] of Object.entries(operation.responses)) {
+    const response = resolveRef(spec, value);
+    const item = document.createElement('li');
+    const types = Object.keys(response.content || {});
+    item.textContent = status + ' ' + response.description + (types.length ? ' (' + types.join(', ') + ')' : '');
+    responses.appendChild(item);
+  }
+  body.appendChild(responses);
+
+  if (tryIt) {
+    const button = document.createElement('button');
+    button.className = 'btn btn-primary';
+    button.textContent = 'Send Request';
+    const output = document.createElement('pre');
+    const code = document.createElement('code');
+    output.appendChild(code);
+    button.addEventListener('click', () => sendRequest(path, inputs, code));
+    body.appendChild(button);
+    body.appendChild(output);
+  }
+
+  return body;
+}
+
+/**
+ * Send a GET request built from the try-it inputs and show the response
+ * @param {string} path - The path template
+ * @param {Array} inputs - The parameters and their input elements
+ * @param {HTMLElement} output - Where to show the response
+ */
+async function sendRequest(path, inputs, output) {
+  let url = path;
+  const query = new URLSearchParams();
+  for (const { param, input } of inputs) {
+    const value = input.value.trim();
+    if (param.in === 'path') {
+      if (!value) {
+        showToast('Missing path parameter ' + param.name, 'warning');
+        return;
+      }
+      url = url.replace('{' + param.name + '}', encodeURIComponent(value));
+    } else if (value) {
+      query.append(param.name, value);
+    }
+  }
+  if (query.toString()) url += '?' + query.toString();
+
+  output.textContent = 'GET ' + url + '\n\nLoading...';
+  try {
+    const response = await fetch(url);
+    const type = response.headers.get('Content-Type') || '';
+    let text;
+    if (type.startsWith('image/')) {
+      text = '(' + type + ' image, ' + (await response.blob()).size + ' bytes)';
+    } else if (type.startsWith('application/json')) {
+      text = JSON.stringify(await response.json(), null, 2);
+    } else {
+      text = await response.text();
+    }
+    output.textContent = 'GET ' + url + '\n' + response.status + ' ' + type + '\n\n' + text;
+  } catch (err) {
+    output.textContent = '';
+    showToast('Request failed: ' + err.message, 'error');
+  }
+}
+
+/**
+ * Load the OpenAPI document and list its operations grouped by tag
+ */
+async function loadDocs() {
+  const container = document.getElementById('docs-endpoints');
+  let spec;
+  try {
+    const response = await fetch(OPENAPI_URL);
+    spec = await response.json();
+  } catch (err) {
+    container.textContent = 'Failed to load the API description.';
+    return;
+  }
+
+  container.innerHTML = '';
+  for (const tag of spec.tags) {
+    const section = document.createElement('section');
+    section.className = 'admin-section';
+    const title = document.createElement('h2');
+    title.className = 'admin-section-title';
+    title.textContent = tag.name;
+    const description = document.createElement('p');
+    description.textContent = tag.description;
+    section.append(title, description);
+
+    for (const path of Object.keys(spec.paths).sort()) {
+      for (const [method, operation] of Object.entries(spec.paths[path])) {
+        if (!operation.tags.includes(tag.name)) continue;
+
+        const details = document.createElement('details');
+        details.className = 'docs-operation';
+        const summary = document.createElement('summary');
+        summary.innerHTML = '<span class="badge docs-method"></span> <code></code> <span class="docs-summary"></span>';
+        summary.querySelector('.docs-method').textContent = method.toUpperCase();
+        summary.querySelector('.docs-method').classList.add(method === 'get' ? 'badge-primary' : 'badge-success');
+        summary.querySelector('code').textContent = path;
+        summary.querySelector('.docs-summary').textContent = operation.summary;
+        details.appendChild(summary);
+
+        // Streams never finish and the WebSocket needs an upgrade, so they
+        // are documented but not tried from here
+        const tryIt = method === 'get' && !operation.tags.includes('Streams');
+        let rendered = false;
+        details.addEventListener('toggle', () => {
+          if (details.open && !rendered) {
+            details.appendChild(renderOperationBody(spec, path, operation, tryIt));
+            rendered = true;
+          }
+        });
+        section.appendChild(details);
+      }
+    }
+    container.appendChild(section);
+  }
+}
+
+document.addEventListener('DOMContentLoaded', loadDocs);
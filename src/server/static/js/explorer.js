@@ -0,0 +1,146 @@
+/**
+ * Anime Quotes API - GraphQL Explorer
+ * Runs queries against /api/graphql and lists the schema from introspection
+ */
+
+const GRAPHQL_ENDPOINT = '/api/graphql';
+
+const INTROSPECTION_QUERY = `{
+  __schema {
+    queryType { name }
+    types {
+      name kind description
+      fields { name description args { name type { ...TypeRef } } type { ...TypeRef } }
+      inputFields { name type { ...TypeRef } }
+    }
+  }
+}
+fragment TypeRef on __Type {
+  kind name
+  ofType { kind name ofType { kind name ofType { kind name } } }
+}`;
+
+/**
+ * Send a query to the GraphQL endpoint
+ * @param {string} query - The GraphQL document
+ * @param {object} variables - Values for the query variables
+ */
+async function fetchGraphQL(query, variables) {
+  const response = await fetch(GRAPHQL_ENDPOINT, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ query, variables }),
+  });
+  return response.json();
+}
+
+/**
+ * Run the query in the editor and show the result
+ */
+async function runGraphQLQuery() {
+  const output = document.getElementById('explorer-output');
+  const query = document.getElementById('explorer-query').value;
+  const variablesText = document.getElementById('explorer-variables').value.trim();
+
+  let variables = {};
+  if (variablesText) {
+    try {
+      variables = JSON.parse(variablesText);
+    } catch (err) {
+      showToast('Variables must be valid JSON', 'error');
+      return;
+    }
+  }
+
+  output.textContent = 'Running...';
+  try {
+    const result = await fetchGraphQL(query, variables);
+    output.textContent = JSON.stringify(result, null, 2);
+    if (result.errors) {
+      showToast(result.errors[0].message, 'warning');
+    }
+  } catch (err) {
+    output.textContent = '';
+    showToast('Request failed: ' + err.message, 'error');
+  }
+}
+
+/**
+ * Format a type reference such as [Quote!]!
+ * @param {object} type - The introspected type reference
+ */
+function formatTypeRef(type) {
+  if (type.kind === 'NON_NULL') return formatTypeRef(type.ofType) + '!';
+  if (type.kind === 'LIST') return '[' + formatTypeRef(type.ofType) + ']';
+  return type.name;
+}
+
+/**
+ * Build the element for one field or input field
+ * @param {object} field - The introspected field
+ */
+function renderSchemaField(field) {
+  const item = document.createElement('li');
+  let signature = field.name;
+  if (field.args && field.args.length > 0) {
+    signature += '(' + field.args.map((arg) => arg.name + ': ' + formatTypeRef(arg.type)).join(', ') + ')';
+  }
+  const code = document.createElement('code');
+  code.textContent = signature + ': ' + formatTypeRef(field.type);
+  item.appendChild(code);
+  if (field.description) {
+    const description = document.createElement('span');
+    description.className = 'explorer-description';
+    description.textContent = ' ' + field.description;
+    item.appendChild(description);
+  }
+  return item;
+}
+
+/**
+ * Load the schema by introspection and list its object and input types
+ */
+async function loadGraphQLSchema() {
+  const container = document.getElementById('explorer-schema');
+  if (!container) return;
+
+  try {
+    const result = await fetchGraphQL(INTROSPECTION_QUERY, {});
+    const schema = result.data.__schema;
+    const types = schema.types
+      .filter((type) => !type.name.startsWith('__') && (type.kind === 'OBJECT' || type.kind === 'INPUT_OBJECT'))
+      .sort((a, b) => (a.name === schema.queryType.name ? -1 : b.name === schema.queryType.name ? 1 : a.name.localeCompare(b.name)));
+
+    container.innerHTML = '';
+    for (const type of types) {
+      const card = document.createElement('div');
+      card.className = 'card';
+      const title = document.createElement('h3');
+      title.textContent = (type.kind === 'INPUT_OBJECT' ? 'input ' : 'type ') + type.name;
+      card.appendChild(title);
+      if (type.description) {
+        const description = document.createElement('p');
+        description.textContent = type.description;
+        card.appendChild(description);
+      }
+      const list = document.createElement('ul');
+      for (const field of type.fields || type.inputFields || []) {
+        list.appendChild(renderSchemaField(field));
+      }
+      card.appendChild(list);
+      container.appendChild(card);
+    }
+  } catch (err) {
+    container.textContent = 'Failed to load the schema.';
+  }
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+  document.getElementById('explorer-query').addEventListener('keydown', (e) => {
+    if (e.ctrlKey && e.key === 'Enter') {
+      e.preventDefault();
+      runGraphQLQuery();
+    }
+  });
+  loadGraphQLSchema();
+});
@@ -207,6 +207,62 @@ async function getRandomQuote() {
   }
 }
 
+/**
+ * Fetch and display a random quote from the given anime
+ * @param {string} anime - Anime name (matched leniently by the API)
+ */
+async function getRandomQuoteFrom(anime) {
+  if (!anime || !anime.trim()) {
+    return getRandomQuote();
+  }
+  try {
+    const response = await fetch(`/api/v1/random?anime=${encodeURIComponent(anime.trim())}`);
+    if (response.status === 404) {
+      showToast(`No quotes found for "${anime}"`, 'warning');
+      return;
+    }
+    if (!response.ok) {
+      throw new Error(`HTTP error ${response.status}`);
+    }
+    displayQuote(await response.json());
+  } catch (error) {
+    showToast(`API Error: ${error.message}`, 'error');
+  }
+}
+
+/**
+ * Fill the anime search datalist with suggestions as the user types
+ */
+function initAnimeSearch() {
+  const input = document.getElementById('anime-search');
+  const list = document.getElementById('anime-suggestions');
+  if (!input || !list) return;
+
+  let timer;
+  input.addEventListener('input', () => {
+    clearTimeout(timer);
+    const prefix = input.value.trim();
+    if (!prefix) return;
+
+    timer = setTimeout(async () => {
+      try {
+        const response = await fetch(`/api/v1/suggest/anime?prefix=${encodeURIComponent(prefix)}`);
+        if (!response.ok) return;
+        const suggestions = await response.json();
+        list.innerHTML = '';
+        suggestions.forEach(s => {
+          const option = document.createElement('option');
+          option.value = s.name;
+          option.label = `${s.count} quotes`;
+          list.appendChild(option);
+        });
+      } catch (error) {
+        console.error('Failed to fetch suggestions:', error);
+      }
+    }, 150);
+  });
+}
+
 /**
  * Display a quote in the quote container
  * @param {object} quote - Quote object with anime, character, and quote properties
@@ -283,6 +339,9 @@ document.addEventListener('DOMContentLoaded', () => {
   // Initialize theme
   initTheme();
 
+  // Anime name autocomplete on the homepage
+  initAnimeSearch();
+
   // Close mobile menu when clicking outside
   document.addEventListener('click', (e) => {
     const nav = document.getElementById('main-nav');
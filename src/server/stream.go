@@ -0,0 +1,128 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+)
+
+// streamFlushRows is how many rows are written between flushes when
+// streaming a list response
+const streamFlushRows = 100
+
+// rowWriter writes a list response one entry at a time
+type rowWriter interface {
+	WriteRow(item interface{}) error
+	Flush() error
+}
+
+// streamRows writes each entry of a list response as it is encoded,
+// flushing regularly so large exports start arriving right away and are
+// never held in memory as a whole
+func streamRows(w http.ResponseWriter, f *responseFormat, status int, items reflect.Value) {
+	w.Header().Set("Content-Type", f.contentType)
+	w.WriteHeader(status)
+
+	flusher, _ := w.(http.Flusher)
+	rw := f.newRowWriter(w, items.Type().Elem())
+	for i := 0; i < items.Len(); i++ {
+		if err := rw.WriteRow(items.Index(i).Interface()); err != nil {
+			// The status is already sent, so all we can do is stop
+			log.Printf("Error streaming %s response: %v", f.name, err)
+			return
+		}
+		if (i+1)%streamFlushRows == 0 {
+			if err := rw.Flush(); err != nil {
+				log.Printf("Error streaming %s response: %v", f.name, err)
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+	if err := rw.Flush(); err != nil {
+		log.Printf("Error streaming %s response: %v", f.name, err)
+	}
+}
+
+// tableRowWriter writes CSV or TSV rows. The header comes from the entry
+// type, so it is written even for an empty list and covers fields the first
+// entry omits; entries whose columns depend on their values (maps) take the
+// header from the first entry instead.
+type tableRowWriter struct {
+	cw      *csv.Writer
+	columns []string
+	header  bool
+}
+
+// newTableRowWriter creates a row writer for entries of type elem using the
+// given field delimiter
+func newTableRowWriter(w io.Writer, elem reflect.Type, comma rune) *tableRowWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	columns, _ := typeColumns(elem)
+	return &tableRowWriter{cw: cw, columns: columns}
+}
+
+// writeHeader writes the header row once, if the columns are known
+func (t *tableRowWriter) writeHeader() error {
+	if t.header || len(t.columns) == 0 {
+		return nil
+	}
+	t.header = true
+	return t.cw.Write(t.columns)
+}
+
+// WriteRow writes one entry in the header's column order
+func (t *tableRowWriter) WriteRow(item interface{}) error {
+	columns, rows := flatten([]node{toNode(reflect.ValueOf(item))})
+	if t.columns == nil {
+		t.columns = columns
+	}
+	if err := t.writeHeader(); err != nil {
+		return err
+	}
+
+	values := make(map[string]string, len(columns))
+	for i, column := range columns {
+		values[column] = rows[0][i]
+	}
+	record := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		record[i] = values[column]
+	}
+	return t.cw.Write(record)
+}
+
+// Flush writes any buffered rows, and the header if no row was written
+func (t *tableRowWriter) Flush() error {
+	if err := t.writeHeader(); err != nil {
+		return err
+	}
+	t.cw.Flush()
+	return t.cw.Error()
+}
+
+// jsonRowWriter writes one JSON document per line
+type jsonRowWriter struct {
+	enc *json.Encoder
+}
+
+// newJSONRowWriter creates an NDJSON row writer
+func newJSONRowWriter(w io.Writer, _ reflect.Type) rowWriter {
+	return &jsonRowWriter{enc: json.NewEncoder(w)}
+}
+
+// WriteRow writes one entry as a line of JSON
+func (j *jsonRowWriter) WriteRow(item interface{}) error {
+	return j.enc.Encode(item)
+}
+
+// Flush is a no-op; the encoder writes each line straight through
+func (j *jsonRowWriter) Flush() error {
+	return nil
+}
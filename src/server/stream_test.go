@@ -0,0 +1,124 @@
+package server
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/apimgr/anime/src/anime"
+)
+
+// streamTable streams items through a table row writer and returns the output
+func streamTable(t *testing.T, items interface{}, comma rune) string {
+	t.Helper()
+	var buf bytes.Buffer
+	v := reflect.ValueOf(items)
+	rw := newTableRowWriter(&buf, v.Type().Elem(), comma)
+	for i := 0; i < v.Len(); i++ {
+		if err := rw.WriteRow(v.Index(i).Interface()); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := rw.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	return buf.String()
+}
+
+type nestedRow struct {
+	Name  string       `json:"name"`
+	Quote *anime.Quote `json:"quote,omitempty"`
+	Tags  []string     `json:"tags,omitempty"`
+	skip  string
+	Skip  string `json:"-"`
+}
+
+func TestTableRowWriter(t *testing.T) {
+	tests := []struct {
+		name  string
+		items interface{}
+		comma rune
+		want  string
+	}{
+		{
+			"later entries keep omitempty fields",
+			[]anime.Character{
+				{Slug: "l", Name: "L", QuoteCount: 1},
+				{Slug: "light", Name: "Light", QuoteCount: 2, Anime: []anime.Anime{{Slug: "death-note", Name: "Death Note", QuoteCount: 2}}},
+			},
+			',',
+			"slug,name,quoteCount,anime,quotes\n" +
+				"l,L,1,,\n" +
+				"light,Light,2,\"slug: death-note, name: Death Note, quoteCount: 2\",\n",
+		},
+		{
+			"empty list still has a header",
+			[]anime.Quote{},
+			',',
+			"id,anime,character,quote\n",
+		},
+		{
+			"tsv",
+			[]anime.Quote{{ID: "1", Anime: "Naruto", Character: "Naruto", Quote: "Believe it!"}},
+			'\t',
+			"id\tanime\tcharacter\tquote\n1\tNaruto\tNaruto\tBelieve it!\n",
+		},
+		{
+			"nested structs become dotted columns",
+			[]nestedRow{
+				{Name: "a"},
+				{Name: "b", Quote: &anime.Quote{ID: "2", Quote: "Hi"}, Tags: []string{"x", "y"}},
+			},
+			',',
+			"name,quote.id,quote.anime,quote.character,quote.quote,tags\n" +
+				"a,,,,,\n" +
+				"b,2,,,Hi,x; y\n",
+		},
+		{
+			"scalars",
+			[]string{"Naruto", "Bleach"},
+			',',
+			"value\nNaruto\nBleach\n",
+		},
+		{
+			"maps take the first entry's columns",
+			[]map[string]string{{"id": "1", "quote": "Hi"}, {"id": "2", "quote": "Yo"}},
+			',',
+			"id,quote\n1,Hi\n2,Yo\n",
+		},
+		{
+			"empty map list",
+			[]map[string]string{},
+			',',
+			"",
+		},
+	}
+	for _, tt := range tests {
+		if got := streamTable(t, tt.items, tt.comma); got != tt.want {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStreamedCSVResponse(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/api/v1/characters.csv", "slug,name,quoteCount,anime,quotes\n"},
+		// No matches still gets a header
+		{"/api/v1/suggest/anime.csv?prefix=zzz", "name,count\n"},
+		{"/api/v1/suggest/anime.tsv?prefix=zzz", "name\tcount\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+		if w.Code != 200 || !strings.HasPrefix(w.Body.String(), tt.want) {
+			t.Errorf("GET %s: %d %q, want a body starting %q", tt.target, w.Code, w.Body.String(), tt.want)
+		}
+	}
+}
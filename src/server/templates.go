@@ -1,9 +1,14 @@
 package server
 
 import (
+	"bytes"
 	"embed"
+	"fmt"
 	"html/template"
+	"io/fs"
 	"net/http"
+	"path"
+	"strings"
 )
 
 // Embed static files and templates
@@ -13,18 +18,51 @@ var staticFS embed.FS
 //go:embed templates/*
 var templateFS embed.FS
 
-var templates *template.Template
+// templates holds one template set per page, each combining base.html with
+// the page that defines its "content" block
+var templates map[string]*template.Template
 
 // initTemplates initializes the HTML templates
 func initTemplates() error {
-	var err error
-	templates, err = template.ParseFS(templateFS, "templates/*.html")
+	files, err := fs.Glob(templateFS, "templates/*.html")
 	if err != nil {
 		return err
 	}
+
+	templates = make(map[string]*template.Template)
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".html")
+		if name == "base" {
+			continue
+		}
+		tmpl, err := template.ParseFS(templateFS, "templates/base.html", file)
+		if err != nil {
+			return err
+		}
+		templates[name] = tmpl
+	}
 	return nil
 }
 
+// renderPage renders a page template inside base.html
+func renderPage(w http.ResponseWriter, status int, page string, data interface{}) error {
+	tmpl, ok := templates[page]
+	if !ok {
+		return fmt.Errorf("template %q not found", page)
+	}
+
+	// Render to a buffer first so template errors can still produce a 500
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "base.html", data); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
 // serveStatic sets up the static file server for CSS, JS, and images
 func (s *Server) serveStatic() http.Handler {
 	return http.FileServer(http.FS(staticFS))